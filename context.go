@@ -1,6 +1,7 @@
 package gecko
 
 import (
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"time"
@@ -13,11 +14,20 @@ type Context struct {
 	response *Response
 	path     string // Patrón de ruta registrado. Ej: "GET /inicio"
 	query    url.Values
+	sse      *StreamSSE // Abierto con c.SSE() y cerrado al terminar el handler.
+
+	multipart          *multipart.Reader // Multipart leído en streaming, ver c.lectorMultipart().
+	multipartConsumido bool              // c.SaveUpload ya leyó el multipart en streaming.
+
+	limiteArchivo int64 // Máximo de bytes por archivo de multipart para esta ruta.
+
 	gecko    *Gecko
 	SesionID string
 	Sesion   any
+	sesion   *Sesion   // Cargada por ServicioSesiones.Middleware.
+	csrf     string    // Token puesto por ProteccionCSRF.Middleware.
 	time     time.Time // Momento en el que se comenzó a procesar la solicitud, utilizado para el log http.
-	Compress bool      // Activar compresión con gzip para Render y RenderOk.
+	Compress bool      // Activar compresión para esta respuesta aunque no esté g.Comprimir.
 }
 
 func (c *Context) Request() *http.Request {
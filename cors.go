@@ -0,0 +1,232 @@
+package gecko
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== CORS ================================================ //
+
+// PoliticaCORS define qué otros orígenes pueden consumir las rutas
+// donde se aplique. Los preflight OPTIONS se responden automáticamente
+// según la política de la ruta a la que se dirigen.
+//
+//	cors := gecko.NuevaPoliticaCORS("https://app.ejemplo.com", "https://*.ejemplo.com")
+//	cors.Credenciales = true
+//
+//	api := g.Group("/api")
+//	api.CORS(cors)
+//	api.GET("/usuarios", s.getUsuariosJSON)
+//
+// También se puede aplicar a todas las rutas con g.CORS() o a una
+// sola con g.GET(...).CORS().
+type PoliticaCORS struct {
+	// Orígenes permitidos, exactos como "https://app.ejemplo.com" o con
+	// comodín de subdominio como "https://*.ejemplo.com". "*" permite
+	// cualquiera pero no se puede combinar con Credenciales.
+	Orígenes []string
+
+	Métodos          []string      // Default: GET, HEAD, POST.
+	Headers          []string      // Headers que puede enviar el cliente. "*" permite los que pida.
+	HeadersExpuestos []string      // Headers de la respuesta que puede leer el cliente.
+	Credenciales     bool          // Permitir cookies y autenticación.
+	MaxAge           time.Duration // Tiempo que el navegador guarda el preflight.
+}
+
+func NuevaPoliticaCORS(orígenes ...string) *PoliticaCORS {
+	return &PoliticaCORS{
+		Orígenes: orígenes,
+		Métodos:  []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		Headers:  []string{HeaderContentType},
+		MaxAge:   10 * time.Minute,
+	}
+}
+
+// Termina el programa si la política es insegura o inválida.
+func (p *PoliticaCORS) validar() {
+	if len(p.Orígenes) == 0 {
+		gko.FatalExitf("gecko.CORS: sin orígenes permitidos")
+	}
+	for _, origen := range p.Orígenes {
+		if origen == "*" {
+			if p.Credenciales {
+				gko.FatalExitf("gecko.CORS: origen '*' no se puede usar con credenciales")
+			}
+			continue
+		}
+		u, err := url.Parse(origen)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			gko.FatalExitf("gecko.CORS: origen inválido '%s', se espera 'https://dominio.com'", origen)
+		}
+		if strings.Contains(u.Host, "*") && !strings.HasPrefix(u.Host, "*.") {
+			gko.FatalExitf("gecko.CORS: comodín solo al inicio del dominio '%s'", origen)
+		}
+	}
+}
+
+// Aplica la política a todas las rutas que no tengan otra.
+func (g *Gecko) CORS(p *PoliticaCORS) {
+	p.validar()
+	g.cors = p
+}
+
+// Aplica la política a las rutas del grupo registradas después
+// y a las respuestas 404 de rutas no registradas con su prefijo.
+func (gr *Grupo) CORS(p *PoliticaCORS) {
+	p.validar()
+	gr.cors = p
+	g := gr.gecko
+	if g.corsGrupos == nil {
+		g.corsGrupos = map[string]*PoliticaCORS{}
+	}
+	g.corsGrupos[gr.prefijo] = p
+}
+
+// Aplica la política solo a esta ruta.
+func (rt *Ruta) CORS(p *PoliticaCORS) *Ruta {
+	p.validar()
+	rt.cors = p
+	return rt
+}
+
+// Política que aplica a la ruta: la suya, la de su grupo o la global.
+func (rt *Ruta) políticaCORS() *PoliticaCORS {
+	if rt.cors != nil {
+		return rt.cors
+	}
+	return rt.gecko.cors
+}
+
+// ================================================================ //
+
+// Reporta si el origen está permitido por la política.
+func (p *PoliticaCORS) permiteOrigen(origen string) bool {
+	u, err := url.Parse(origen)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, permitido := range p.Orígenes {
+		if permitido == "*" || strings.EqualFold(permitido, origen) {
+			return true
+		}
+		esquema, host, ok := strings.Cut(permitido, "://*.")
+		if !ok || !strings.EqualFold(esquema, u.Scheme) {
+			continue
+		}
+		// "https://*.ejemplo.com" permite "https://a.ejemplo.com" y
+		// "https://a.b.ejemplo.com" pero no "https://ejemplo.com".
+		if len(u.Host) > len(host)+1 && strings.HasSuffix(strings.ToLower(u.Host), "."+strings.ToLower(host)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Agrega los headers CORS a una solicitud normal (no preflight).
+// Se aplica antes del handler para que también los errores sean legibles.
+func (p *PoliticaCORS) agregarHeaders(c *Context) {
+	h := c.response.Header()
+	h.Add(HeaderVary, HeaderOrigin)
+	origen := c.request.Header.Get(HeaderOrigin)
+	if origen == "" || !p.permiteOrigen(origen) {
+		return
+	}
+	p.ponerOrigen(h, origen)
+	if len(p.HeadersExpuestos) > 0 {
+		h.Set(HeaderAccessControlExposeHeaders, strings.Join(p.HeadersExpuestos, ", "))
+	}
+}
+
+func (p *PoliticaCORS) ponerOrigen(h http.Header, origen string) {
+	if slices.Contains(p.Orígenes, "*") && !p.Credenciales {
+		h.Set(HeaderAccessControlAllowOrigin, "*")
+	} else {
+		h.Set(HeaderAccessControlAllowOrigin, origen)
+	}
+	if p.Credenciales {
+		h.Set(HeaderAccessControlAllowCredentials, "true")
+	}
+}
+
+// Responde un preflight OPTIONS. Si no está permitido se responde
+// sin headers CORS para que el navegador bloquee la solicitud real.
+func (p *PoliticaCORS) responderPreflight(c *Context) error {
+	h := c.response.Header()
+	h.Add(HeaderVary, HeaderOrigin)
+	h.Add(HeaderVary, HeaderAccessControlRequestMethod)
+	h.Add(HeaderVary, HeaderAccessControlRequestHeaders)
+
+	origen := c.request.Header.Get(HeaderOrigin)
+	método := c.request.Header.Get(HeaderAccessControlRequestMethod)
+	if !p.permiteOrigen(origen) || !slices.Contains(p.Métodos, método) {
+		return c.NoContent(http.StatusNoContent)
+	}
+	pedidos := c.request.Header.Get(HeaderAccessControlRequestHeaders)
+	if pedidos != "" {
+		for _, header := range strings.Split(pedidos, ",") {
+			header = strings.TrimSpace(header)
+			if !slices.Contains(p.Headers, "*") && !slices.ContainsFunc(p.Headers, func(permitido string) bool {
+				return strings.EqualFold(permitido, header)
+			}) {
+				return c.NoContent(http.StatusNoContent)
+			}
+		}
+	}
+
+	p.ponerOrigen(h, origen)
+	h.Set(HeaderAccessControlAllowMethods, strings.Join(p.Métodos, ", "))
+	if pedidos != "" {
+		h.Set(HeaderAccessControlAllowHeaders, pedidos)
+	}
+	if p.MaxAge > 0 {
+		h.Set(HeaderAccessControlMaxAge, strconv.Itoa(int(p.MaxAge.Seconds())))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Si la solicitud es un preflight CORS para una ruta con política,
+// lo responde y retorna true.
+func (g *Gecko) manejarPreflightCORS(c *Context) bool {
+	r := c.request
+	método := r.Header.Get(HeaderAccessControlRequestMethod)
+	if r.Method != http.MethodOptions || método == "" || r.Header.Get(HeaderOrigin) == "" {
+		return false
+	}
+	prueba := r.Clone(r.Context())
+	prueba.Method = método
+	_, patrón := g.mux.Handler(prueba)
+	rt, ok := g.rutasPorPatrón[patrón]
+	if !ok || rt.políticaCORS() == nil {
+		return false
+	}
+	rt.políticaCORS().responderPreflight(c)
+	return true
+}
+
+// Política para las respuestas 404 y 405: la de una ruta con el mismo
+// path y otro método, la del grupo con el prefijo más largo que coincida,
+// o la global.
+func (g *Gecko) políticaCORSSinRuta(r *http.Request, permitidos []string) *PoliticaCORS {
+	for _, método := range permitidos {
+		prueba := r.Clone(r.Context())
+		prueba.Method = método
+		_, patrón := g.mux.Handler(prueba)
+		if rt, ok := g.rutasPorPatrón[patrón]; ok && rt.políticaCORS() != nil {
+			return rt.políticaCORS()
+		}
+	}
+	política, largo := g.cors, -1
+	for prefijo, p := range g.corsGrupos {
+		if len(prefijo) > largo && (r.URL.Path == prefijo || strings.HasPrefix(r.URL.Path, prefijo+"/")) {
+			política, largo = p, len(prefijo)
+		}
+	}
+	return política
+}
@@ -0,0 +1,121 @@
+package gecko
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPermiteOrigen(t *testing.T) {
+	p := NuevaPoliticaCORS("https://app.ejemplo.com", "https://*.cliente.com")
+	casos := map[string]bool{
+		"https://app.ejemplo.com":   true,
+		"https://APP.ejemplo.com":   true,
+		"http://app.ejemplo.com":    false,
+		"https://otra.ejemplo.com":  false,
+		"https://a.cliente.com":     true,
+		"https://a.b.cliente.com":   true,
+		"https://cliente.com":       false,
+		"https://malcliente.com":    false,
+		"http://a.cliente.com":      false,
+		"https://a.cliente.com.mal": false,
+		"":                          false,
+		"null":                      false,
+	}
+	for origen, esperado := range casos {
+		if p.permiteOrigen(origen) != esperado {
+			t.Errorf("origen %q: se esperaba %v", origen, esperado)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	const (
+		origenGlobal = "https://global.com"
+		origenAPI    = "https://app.ejemplo.com"
+	)
+	var ejecutóOptions bool
+	ok := func(c *Context) error { return c.StringOk("ok") }
+	g := New()
+	g.CORS(NuevaPoliticaCORS(origenGlobal))
+	g.GET("/publico", ok)
+
+	cors := NuevaPoliticaCORS(origenAPI)
+	cors.Métodos = append(cors.Métodos, http.MethodDelete)
+	cors.Headers = append(cors.Headers, "X-Token")
+	cors.HeadersExpuestos = []string{"X-Total"}
+	cors.Credenciales = true
+	api := g.Group("/api")
+	api.CORS(cors)
+	api.GET("/", ok)
+	api.GET("/usuarios", ok)
+	api.DELETE("/usuarios/{id}", ok)
+	g.OPTIONS("/api/usuarios/{id}", func(c *Context) error {
+		ejecutóOptions = true
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	casos := []struct {
+		nombre    string
+		método    string
+		ruta      string
+		origen    string
+		preflight string // Access-Control-Request-Method.
+		headers   string // Access-Control-Request-Headers.
+		status    int
+		acao      string // Access-Control-Allow-Origin esperado.
+		options   bool   // Se ejecutó el handler OPTIONS.
+	}{
+		{"preflight ruta global", "OPTIONS", "/publico", origenGlobal, "GET", "", 204, origenGlobal, false},
+		{"preflight origen ajeno", "OPTIONS", "/publico", origenAPI, "GET", "", 204, "", false},
+		{"preflight método ajeno", "OPTIONS", "/publico", origenGlobal, "PUT", "", 204, origenGlobal, false},
+		{"preflight grupo", "OPTIONS", "/api/usuarios", origenAPI, "GET", "x-token", 204, origenAPI, false},
+		{"preflight header ajeno", "OPTIONS", "/api/usuarios", origenAPI, "GET", "X-Otro", 204, "", false},
+		{"preflight raíz del grupo", "OPTIONS", "/api", origenAPI, "GET", "", 204, origenAPI, false},
+		{"preflight con OPTIONS propio", "OPTIONS", "/api/usuarios/3", origenAPI, "DELETE", "", 204, origenAPI, false},
+		{"OPTIONS normal", "OPTIONS", "/api/usuarios/3", origenGlobal, "", "", 204, origenGlobal, true},
+		{"solicitud", "GET", "/api/usuarios", origenAPI, "", "", 200, origenAPI, false},
+		{"solicitud origen ajeno", "GET", "/api/usuarios", origenGlobal, "", "", 200, "", false},
+		{"404 en grupo", "GET", "/api/nada/x", origenAPI, "", "", 404, origenAPI, false},
+		{"404 fuera del grupo", "GET", "/apis", origenAPI, "", "", 404, "", false},
+		{"405 en grupo", "PUT", "/api/usuarios", origenAPI, "", "", 405, origenAPI, false},
+		{"405 global", "POST", "/publico", origenGlobal, "", "", 405, origenGlobal, false},
+		{"404 global", "GET", "/nada", origenGlobal, "", "", 404, origenGlobal, false},
+	}
+	for _, caso := range casos {
+		ejecutóOptions = false
+		req := httptest.NewRequest(caso.método, caso.ruta, nil)
+		req.Header.Set(HeaderOrigin, caso.origen)
+		if caso.preflight != "" {
+			req.Header.Set(HeaderAccessControlRequestMethod, caso.preflight)
+		}
+		if caso.headers != "" {
+			req.Header.Set(HeaderAccessControlRequestHeaders, caso.headers)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status || rec.Header().Get(HeaderAccessControlAllowOrigin) != caso.acao || ejecutóOptions != caso.options {
+			t.Errorf("%s: status %d, Allow-Origin %q, handler OPTIONS %v",
+				caso.nombre, rec.Code, rec.Header().Get(HeaderAccessControlAllowOrigin), ejecutóOptions)
+		}
+	}
+}
+
+// Un OPTIONS que atrapa todo el prefijo del grupo no choca con el
+// preflight, que se responde desde la ruta o el handler sin ruta.
+func TestCORSOptionsComodin(t *testing.T) {
+	g := New()
+	api := g.Group("/api")
+	api.CORS(NuevaPoliticaCORS("https://app.ejemplo.com"))
+	api.GET("/usuarios", func(c *Context) error { return c.StringOk("ok") })
+	g.OPTIONS("/api/{resto...}", func(c *Context) error { return c.StringOk("options") })
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/usuarios", nil)
+	req.Header.Set(HeaderOrigin, "https://app.ejemplo.com")
+	req.Header.Set(HeaderAccessControlRequestMethod, http.MethodGet)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get(HeaderAccessControlAllowOrigin) != "https://app.ejemplo.com" {
+		t.Errorf("preflight: status %d, headers %v", rec.Code, rec.Header())
+	}
+}
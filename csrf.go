@@ -0,0 +1,163 @@
+package gecko
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkoid"
+)
+
+const (
+	CampoCSRF        = "csrf_token" // Nombre del input hidden en formularios.
+	cookieCSRF       = "csrf"       // Para solicitudes sin sesión.
+	datoSesionCSRF   = "csrf"       // Clave en Sesion.Datos.
+	largoTokenCSRF   = 32
+	maxAgeCookieCSRF = 24 * 60 * 60 // Segundos.
+)
+
+// Protección contra Cross-Site Request Forgery para solicitudes que
+// modifican datos (POST, PUT, PATCH, DELETE).
+//
+// Cada sesión tiene su propio token guardado en Sesion.Datos. Si la
+// solicitud no tiene sesión (ej. formulario de login) el token se
+// guarda en una cookie.
+//
+// Las solicitudes HTMX deben enviar el token en el header X-CSRF-Token,
+// lo cual hace automáticamente la extensión gecko.js, y los formularios
+// normales en el campo "csrf_token" con {{ csrfInput .CSRFToken }}.
+//
+// En los formularios multipart sin HTMX el campo debe ser el primero
+// porque solo se lee esa parte antes del handler, y así el resto queda
+// para guardar archivos en streaming con c.SaveUpload:
+//
+//	<form method="post" enctype="multipart/form-data">
+//		{{ csrfInput .CSRFToken }}
+//		<input type="file" name="foto">
+//	</form>
+//
+//	csrf := gecko.NuevaProteccionCSRF(sesiones)
+//	g.Use(sesiones.Middleware, csrf.Middleware)
+type ProteccionCSRF struct {
+	Sesiones *ServicioSesiones // Para guardar el token en la sesión.
+}
+
+func NuevaProteccionCSRF(sesiones *ServicioSesiones) *ProteccionCSRF {
+	return &ProteccionCSRF{Sesiones: sesiones}
+}
+
+// Token CSRF para la solicitud actual puesto por el middleware.
+// Se pasa automáticamente como .CSRFToken a las plantillas con RenderOk.
+func (c *Context) CSRFToken() string {
+	return c.csrf
+}
+
+// Middleware que asegura que la solicitud tenga un token CSRF y lo
+// verifica si el método no es seguro. Si no coincide responde con
+// gko.ErrCSRF (403) sin ejecutar el handler.
+//
+// Debe ir después del middleware de sesiones.
+func (p *ProteccionCSRF) Middleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		op := gko.Op("gecko.CSRF")
+		token, err := p.obtenerToken(c)
+		if err != nil {
+			return op.Err(err)
+		}
+		c.csrf = token
+
+		switch c.request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			return next(c)
+		}
+
+		recibido := c.request.Header.Get(HeaderXCSRFToken)
+		if recibido == "" && !c.EsHTMX() {
+			recibido, err = c.tokenCSRFFormulario()
+			if err != nil {
+				return op.Err(err)
+			}
+		}
+		if recibido == "" {
+			return op.E(gko.ErrCSRF).Msg("Solicitud sin token de seguridad, recargue la página")
+		}
+		if subtle.ConstantTimeCompare([]byte(recibido), []byte(token)) != 1 {
+			return op.E(gko.ErrCSRF).Msg("Token de seguridad inválido, recargue la página")
+		}
+		return next(c)
+	}
+}
+
+// Obtiene el token de la sesión o de la cookie, y si no hay lo genera.
+func (p *ProteccionCSRF) obtenerToken(c *Context) (string, error) {
+	if sesion := c.GetSesion(); sesion != nil && p.Sesiones != nil {
+		if token := sesion.Datos[datoSesionCSRF]; token != "" {
+			return token, nil
+		}
+		token, err := gkoid.New64(largoTokenCSRF)
+		if err != nil {
+			return "", err
+		}
+		if sesion.Datos == nil {
+			sesion.Datos = map[string]string{}
+		}
+		sesion.Datos[datoSesionCSRF] = token
+		return token, p.Sesiones.Guardar(c)
+	}
+
+	if cookie, err := c.Cookie(cookieCSRF); err == nil && len(cookie.Value) == largoTokenCSRF {
+		return cookie.Value, nil
+	}
+	token, err := gkoid.New64(largoTokenCSRF)
+	if err != nil {
+		return "", err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     cookieCSRF,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAgeCookieCSRF,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, nil
+}
+
+// Token enviado por un formulario normal. Si el body excede el límite
+// de la ruta se regresa gko.ErrTooBig en lugar de tomarlo como ausente.
+func (c *Context) tokenCSRFFormulario() (string, error) {
+	if strings.HasPrefix(c.request.Header.Get(HeaderContentType), MIMEMultipartForm) {
+		return c.tokenCSRFMultipart()
+	}
+	if err := c.request.ParseForm(); err != nil {
+		return "", errorBody(err)
+	}
+	return c.request.Form.Get(CampoCSRF), nil
+}
+
+// Token en la primera parte del multipart. Si es otro campo se toma
+// como ausente. El resto del body se queda sin leer.
+func (c *Context) tokenCSRFMultipart() (string, error) {
+	mr, err := c.lectorMultipart()
+	if err != nil {
+		return "", gko.ErrDatoInvalido.Err(err).Msg("Formulario multipart inválido")
+	}
+	part, err := mr.NextPart()
+	if err == io.EOF {
+		return "", nil
+	}
+	if err != nil {
+		return "", errorBody(err)
+	}
+	if part.FormName() != CampoCSRF || part.FileName() != "" {
+		part.Close()
+		return "", nil
+	}
+	if err := c.agregarValorForm(part); err != nil {
+		return "", err
+	}
+	return c.request.PostForm.Get(CampoCSRF), nil
+}
@@ -0,0 +1,163 @@
+package gecko
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Ejecuta la solicitud en g con las cookies dadas.
+func solicitudCSRF(g *Gecko, req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func postForm(ruta string, valores url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, ruta, strings.NewReader(valores.Encode()))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	return req
+}
+
+func TestCSRFCookie(t *testing.T) {
+	g := New()
+	csrf := NuevaProteccionCSRF(nil)
+	g.Use(csrf.Middleware)
+	g.GET("/form", func(c *Context) error { return c.StringOk(c.CSRFToken()) })
+	g.POST("/form", func(c *Context) error { return c.StringOk("ok") })
+
+	rec := solicitudCSRF(g, httptest.NewRequest(http.MethodGet, "/form", nil))
+	token := rec.Body.String()
+	var cookie *http.Cookie
+	for _, ck := range rec.Result().Cookies() {
+		if ck.Name == cookieCSRF {
+			cookie = ck
+		}
+	}
+	if cookie == nil || cookie.Value != token || len(token) != largoTokenCSRF {
+		t.Fatalf("cookie %v con token %q", cookie, token)
+	}
+
+	casos := []struct {
+		nombre string
+		req    *http.Request
+		status int
+	}{
+		{"campo válido", postForm("/form", url.Values{CampoCSRF: {token}}), http.StatusOK},
+		{"campo inválido", postForm("/form", url.Values{CampoCSRF: {"x" + token[1:]}}), http.StatusForbidden},
+		{"sin token", postForm("/form", url.Values{"nombre": {"a"}}), http.StatusForbidden},
+	}
+	for _, caso := range casos {
+		if rec := solicitudCSRF(g, caso.req, cookie); rec.Code != caso.status {
+			t.Errorf("%s: status %d, se esperaba %d", caso.nombre, rec.Code, caso.status)
+		}
+	}
+	// Sin la cookie se genera otro token que no coincide.
+	if rec := solicitudCSRF(g, postForm("/form", url.Values{CampoCSRF: {token}})); rec.Code != http.StatusForbidden {
+		t.Errorf("sin cookie: status %d", rec.Code)
+	}
+}
+
+func TestCSRFSesionHTMX(t *testing.T) {
+	store := NuevoSessionStoreMemoria()
+	sesion := &Sesion{ID: "sesion-de-prueba", Creada: time.Now(), Expira: time.Now().Add(time.Hour),
+		Datos: map[string]string{datoSesionCSRF: "token-de-la-sesion"}}
+	if err := store.Save(sesion); err != nil {
+		t.Fatal(err)
+	}
+	sesiones := NuevoServicioSesiones(store)
+	g := New()
+	g.Use(sesiones.Middleware, NuevaProteccionCSRF(sesiones).Middleware)
+	g.POST("/accion", func(c *Context) error { return c.StringOk("ok") })
+	cookie := &http.Cookie{Name: defaultSesionCookie, Value: sesion.ID}
+
+	// HTMX en el header.
+	req := httptest.NewRequest(http.MethodPost, "/accion", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set(HeaderXCSRFToken, "token-de-la-sesion")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusOK {
+		t.Errorf("htmx con header: status %d", rec.Code)
+	}
+	// HTMX no lo puede enviar solo en el form.
+	req = postForm("/accion", url.Values{CampoCSRF: {"token-de-la-sesion"}})
+	req.Header.Set("HX-Request", "true")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("htmx sin header: status %d", rec.Code)
+	}
+	// Token de otra sesión.
+	req = httptest.NewRequest(http.MethodPost, "/accion", nil)
+	req.Header.Set(HeaderXCSRFToken, "token-de-otra-sesion")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("token ajeno: status %d", rec.Code)
+	}
+}
+
+func TestCSRFNoLeeBody(t *testing.T) {
+	g := New()
+	g.Use(NuevaProteccionCSRF(nil).Middleware)
+	dir := t.TempDir()
+	var streaming bool
+	g.POST("/subir", func(c *Context) error {
+		streaming = c.multipart != nil
+		archivo, err := c.SaveUpload("archivo", dir, OpcionesUpload{})
+		if err != nil {
+			return err
+		}
+		return c.StringOk(c.FormValue("nombre") + " " + archivo.NombreOriginal)
+	})
+	g.POST("/form", func(c *Context) error { return c.StringOk(c.FormVal("nombre")) })
+	g.POST("/chico", func(c *Context) error { return c.StringOk("ok") }).LimitarBody(100)
+	cookie := &http.Cookie{Name: cookieCSRF, Value: strings.Repeat("t", largoTokenCSRF)}
+
+	multipartCSRF := func(ruta string, campos ...string) *http.Request {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for i := 0; i < len(campos); i += 2 {
+			if campos[i] == "archivo" {
+				fw, _ := mw.CreateFormFile("archivo", "notas.txt")
+				fw.Write([]byte(campos[i+1]))
+			} else {
+				mw.WriteField(campos[i], campos[i+1])
+			}
+		}
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, ruta, &body)
+		req.Header.Set(HeaderContentType, mw.FormDataContentType())
+		return req
+	}
+
+	// El token va en la primera parte y el resto queda para el handler.
+	req := multipartCSRF("/subir", CampoCSRF, cookie.Value, "nombre", "a", "archivo", "hola")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusOK || !streaming || rec.Body.String() != "a notas.txt" {
+		t.Errorf("multipart: status %d, streaming %v, body %q", rec.Code, streaming, rec.Body.String())
+	}
+	// Los valores se leen sin SaveUpload.
+	req = multipartCSRF("/form", CampoCSRF, cookie.Value, "nombre", " b ")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusOK || rec.Body.String() != "b" {
+		t.Errorf("multipart sin archivo: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	// El token en otra parte o en el query no se acepta.
+	req = multipartCSRF("/form", "nombre", "a", CampoCSRF, cookie.Value)
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("token después de otro campo: status %d", rec.Code)
+	}
+	req = multipartCSRF("/form?"+CampoCSRF+"="+cookie.Value, "nombre", "a")
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusForbidden {
+		t.Errorf("token en el query: status %d", rec.Code)
+	}
+
+	// Un body que excede el límite es 413, no 403 por falta de token.
+	req = postForm("/chico", url.Values{"relleno": {strings.Repeat("x", 200)}, CampoCSRF: {cookie.Value}})
+	req.ContentLength = -1
+	if rec := solicitudCSRF(g, req, cookie); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("body grande: status %d", rec.Code)
+	}
+}
@@ -42,3 +42,24 @@ func NuevoEventRepoSqlite(db sqlitedb.Ejecutor) (*EventRepoSqlite, error) {
 
 	return &EventRepoSqlite{}, nil
 }
+
+// EventStore que guarda los eventos en la transacción dada para que se
+// confirmen junto con los cambios del dominio. El broadcaster, que puede
+// ser nil, solo recibe los eventos cuando la transacción se confirma.
+func (r *EventRepoSqlite) EventStoreTx(tx *sqlitedb.Transaccion, broadcaster gko.EventBroadcaster) *gko.EventStore {
+	store := &gko.EventStore{Repo: r.NuevoRepoWrite(tx)}
+	if broadcaster != nil {
+		store.Broadcaster = &broadcastAlConfirmar{tx: tx, broadcaster: broadcaster}
+	}
+	return store
+}
+
+// Retiene los eventos hasta que se confirme la transacción.
+type broadcastAlConfirmar struct {
+	tx          *sqlitedb.Transaccion
+	broadcaster gko.EventBroadcaster
+}
+
+func (b *broadcastAlConfirmar) Broadcast(ev gko.Event) {
+	b.tx.AlConfirmar(func() { b.broadcaster.Broadcast(ev) })
+}
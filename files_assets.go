@@ -0,0 +1,220 @@
+package gecko
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== ASSETS CON HUELLA =================================== //
+
+// Caracteres hexadecimales del hash que se agregan al nombre del archivo.
+const largoHuellaAsset = 10
+
+// Cache para URLs con huella, que cambian cuando cambia el contenido.
+const cacheAssetInmutable = "public, max-age=31536000, immutable"
+
+// Assets sirve archivos estáticos con una huella de su contenido en el
+// nombre para que el navegador los guarde indefinidamente, por ejemplo
+// "css/app.css" se sirve también como "css/app.3f2a1b9c0d.css".
+//
+// Si existen archivos hermanos "app.css.br" o "app.css.gz" se envían
+// en lugar del original a los clientes que los acepten.
+//
+// Crear con g.StaticAssets y usar a.URL() o la función "asset" de
+// plantillas para obtener la URL con huella.
+type Assets struct {
+	rutaWeb   string
+	fsys      fs.FS
+	porRuta   map[string]*asset // "css/app.css"
+	porHuella map[string]*asset // "css/app.3f2a1b9c0d.css"
+}
+
+type asset struct {
+	ruta       string            // Relativa al fs. Ej: "css/app.css".
+	rutaHuella string            // Ej: "css/app.3f2a1b9c0d.css".
+	huella     string            // Hash del contenido original.
+	mimeType   string            // Del archivo original.
+	modTime    time.Time         // Del archivo original.
+	variantes  map[string]string // Content-Encoding → ruta del archivo precomprimido.
+}
+
+// Registra una ruta para servir los archivos del filesystem calculando
+// la huella de todos al iniciar. Termina el programa si no puede leerlos.
+//
+//	assets := g.StaticAssets("/static", os.DirFS("static"))
+//	tmpls.UsarAssets(assets)
+//
+//	<link rel="stylesheet" href="{{ asset "css/app.css" }}">
+//
+// Las URLs con huella se sirven con Cache-Control immutable. Las URLs
+// sin huella siguen funcionando pero el navegador debe revalidarlas.
+func (g *Gecko) StaticAssets(rutaWeb string, fsys fs.FS) *Assets {
+	a, err := NuevoAssets(rutaWeb, fsys)
+	if err != nil {
+		gko.FatalError(err)
+	}
+	g.registrarRuta(http.MethodGet, path.Join(rutaWeb, "{fpath...}"), a.servir)
+	return a
+}
+
+// Calcula las huellas de todos los archivos del filesystem.
+// Preferir g.StaticAssets que además registra la ruta.
+func NuevoAssets(rutaWeb string, fsys fs.FS) (*Assets, error) {
+	op := gko.Op("gecko.NuevoAssets")
+	a := &Assets{
+		rutaWeb:   "/" + strings.Trim(rutaWeb, "/"),
+		fsys:      fsys,
+		porRuta:   map[string]*asset{},
+		porHuella: map[string]*asset{},
+	}
+	precomprimidos := map[string]string{} // ruta → encoding
+	err := fs.WalkDir(fsys, ".", func(ruta string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(ruta, ".br") {
+			precomprimidos[ruta] = "br"
+			return nil
+		}
+		if strings.HasSuffix(ruta, ".gz") {
+			precomprimidos[ruta] = "gzip"
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		huella, err := huellaArchivo(fsys, ruta)
+		if err != nil {
+			return err
+		}
+		ast := &asset{
+			ruta:       ruta,
+			rutaHuella: agregarHuella(ruta, huella),
+			huella:     huella,
+			mimeType:   mime.TypeByExtension(path.Ext(ruta)),
+			modTime:    info.ModTime(),
+			variantes:  map[string]string{},
+		}
+		a.porRuta[ast.ruta] = ast
+		a.porHuella[ast.rutaHuella] = ast
+		return nil
+	})
+	if err != nil {
+		return nil, op.Err(err)
+	}
+	for ruta, encoding := range precomprimidos {
+		original := ruta[:len(ruta)-len(path.Ext(ruta))]
+		if ast, ok := a.porRuta[original]; ok {
+			ast.variantes[encoding] = ruta
+		} else {
+			// Archivo .gz o .br sin original: se sirve tal cual.
+			a.porRuta[ruta] = &asset{ruta: ruta, rutaHuella: ruta, mimeType: mime.TypeByExtension(path.Ext(ruta))}
+		}
+	}
+	return a, nil
+}
+
+// URL con huella para el archivo en la ruta relativa al filesystem.
+// Si el archivo no existe se registra un aviso y se devuelve sin huella.
+func (a *Assets) URL(ruta string) string {
+	ruta = strings.TrimPrefix(ruta, "/")
+	ast, ok := a.porRuta[ruta]
+	if !ok {
+		gko.LogWarnf("gecko.Assets: asset '%s' no existe", ruta)
+		return path.Join(a.rutaWeb, ruta)
+	}
+	return path.Join(a.rutaWeb, ast.rutaHuella)
+}
+
+func (a *Assets) servir(c *Context) error {
+	fpath := c.Param("fpath")
+	ast, inmutable := a.porHuella[fpath]
+	if !inmutable {
+		var ok bool
+		ast, ok = a.porRuta[fpath]
+		if !ok {
+			return gko.ErrNoEncontrado
+		}
+	}
+	h := c.response.Header()
+	if inmutable {
+		h.Set(HeaderCacheControl, cacheAssetInmutable)
+	} else {
+		h.Set(HeaderCacheControl, "no-cache")
+	}
+	if ast.mimeType != "" {
+		h.Set(HeaderContentType, ast.mimeType)
+	}
+
+	// Elegir la variante precomprimida que prefiera el cliente.
+	archivo, encoding := ast.ruta, ""
+	if len(ast.variantes) > 0 {
+		h.Add(HeaderVary, HeaderAcceptEncoding)
+		calidades := calidadesEncoding(c.request.Header.Get(HeaderAcceptEncoding))
+		mejorQ := 0.0
+		for _, enc := range []string{"br", "gzip"} { // Orden de preferencia.
+			if ruta, ok := ast.variantes[enc]; ok && calidades[enc] > mejorQ {
+				archivo, encoding, mejorQ = ruta, enc, calidades[enc]
+			}
+		}
+	}
+	if encoding != "" {
+		h.Set(HeaderContentEncoding, encoding)
+	}
+	if ast.huella != "" {
+		etag := ast.huella
+		if encoding != "" {
+			etag += "-" + encoding
+		}
+		h.Set("ETag", `"`+etag+`"`)
+	}
+
+	file, err := a.fsys.Open(archivo)
+	if err != nil {
+		gko.Err(err).Op("Assets.Open('" + archivo + "')").Log()
+		return gko.ErrNoEncontrado
+	}
+	defer file.Close()
+	ff, ok := file.(io.ReadSeeker)
+	if !ok {
+		return gko.ErrInesperado.Str("file does not implement io.ReadSeeker")
+	}
+	http.ServeContent(c.response, c.request, path.Base(ast.ruta), ast.modTime, ff)
+	return nil
+}
+
+func huellaArchivo(fsys fs.FS, ruta string) (string, error) {
+	f, err := fsys.Open(ruta)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:largoHuellaAsset], nil
+}
+
+// Inserta la huella antes de la extensión: "css/app.css" → "css/app.<huella>.css".
+func agregarHuella(ruta, huella string) string {
+	ext := path.Ext(ruta)
+	if ext == "" || path.Base(ruta) == ext { // Sin extensión o ".oculto".
+		return ruta + "." + huella
+	}
+	return strings.TrimSuffix(ruta, ext) + "." + huella + ext
+}
@@ -0,0 +1,98 @@
+package gecko
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticAssets(t *testing.T) {
+	css := []byte("body { color: black; }")
+	fsys := fstest.MapFS{
+		"css/app.css":    {Data: css},
+		"css/app.css.gz": {Data: []byte("gzip")},
+		"css/app.css.br": {Data: []byte("brotli")},
+		"js/app.js":      {Data: []byte("console.log(1)")},
+		"LICENSE":        {Data: []byte("MIT")},
+		"solo.txt.gz":    {Data: []byte("sin original")},
+	}
+	g := New()
+	assets := g.StaticAssets("/static/", fsys)
+	suma := sha256.Sum256(css)
+	huella := hex.EncodeToString(suma[:])[:largoHuellaAsset]
+
+	urls := map[string]string{
+		"css/app.css":  "/static/css/app." + huella + ".css",
+		"/css/app.css": "/static/css/app." + huella + ".css",
+		"no/existe.js": "/static/no/existe.js",
+		"solo.txt.gz":  "/static/solo.txt.gz",
+	}
+	for ruta, esperada := range urls {
+		if url := assets.URL(ruta); url != esperada {
+			t.Errorf("URL(%q) = %q, se esperaba %q", ruta, url, esperada)
+		}
+	}
+	if url := assets.URL("LICENSE"); url == "/static/LICENSE" || len(url) != len("/static/LICENSE.")+largoHuellaAsset {
+		t.Errorf("URL de archivo sin extensión: %q", url)
+	}
+
+	casos := []struct {
+		nombre   string
+		ruta     string
+		accept   string
+		status   int
+		cache    string
+		encoding string
+		body     string
+	}{
+		{"con huella", urls["css/app.css"], "", http.StatusOK, cacheAssetInmutable, "", string(css)},
+		{"sin huella", "/static/css/app.css", "", http.StatusOK, "no-cache", "", string(css)},
+		{"brotli", urls["css/app.css"], "gzip, br", http.StatusOK, cacheAssetInmutable, "br", "brotli"},
+		{"gzip por q", urls["css/app.css"], "br;q=0.5, gzip", http.StatusOK, cacheAssetInmutable, "gzip", "gzip"},
+		{"gzip", "/static/css/app.css", "gzip, deflate", http.StatusOK, "no-cache", "gzip", "gzip"},
+		{"encoding rechazado", urls["css/app.css"], "br;q=0, gzip;q=0", http.StatusOK, cacheAssetInmutable, "", string(css)},
+		{"sin variantes", "/static/js/app.js", "br, gzip", http.StatusOK, "no-cache", "", "console.log(1)"},
+		{"huella vieja", "/static/css/app.0000000000.css", "", http.StatusNotFound, "", "", ""},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(http.MethodGet, caso.ruta, nil)
+		if caso.accept != "" {
+			req.Header.Set(HeaderAcceptEncoding, caso.accept)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status {
+			t.Errorf("%s: status %d", caso.nombre, rec.Code)
+			continue
+		}
+		if caso.status != http.StatusOK {
+			continue
+		}
+		h := rec.Header()
+		if h.Get(HeaderCacheControl) != caso.cache || h.Get(HeaderContentEncoding) != caso.encoding || rec.Body.String() != caso.body {
+			t.Errorf("%s: Cache-Control %q, Content-Encoding %q, body %q",
+				caso.nombre, h.Get(HeaderCacheControl), h.Get(HeaderContentEncoding), rec.Body.String())
+		}
+		if caso.ruta != "/static/js/app.js" && h.Get(HeaderVary) != HeaderAcceptEncoding {
+			t.Errorf("%s: Vary %q", caso.nombre, h.Get(HeaderVary))
+		}
+	}
+
+	// El ETag distingue la variante y permite responder 304.
+	req := httptest.NewRequest(http.MethodGet, urls["css/app.css"], nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if etag := rec.Header().Get("ETag"); etag != `"`+huella+`-gzip"` {
+		t.Errorf("ETag %q", etag)
+	}
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: status %d", rec.Code)
+	}
+}
@@ -1,13 +1,17 @@
 package gecko
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/pargomx/gecko/gko"
 )
@@ -34,10 +38,35 @@ type Gecko struct {
 
 	staticFiles map[string]staticResource
 
+	middlewares      []MiddlewareFunc   // Globales para todas las rutas. Ver g.Use().
+	middlewaresMu    sync.Mutex         // Para g.Use() y componer las rutas.
+	middlewaresFijos bool               // Ya se compuso alguna ruta y no se pueden agregar.
+	sinRuta          func() HandlerFunc // Ver g.responderSinRuta.
+
+	rutas          []*Ruta                  // Registradas en orden. Ver g.Routes().
+	rutasPorNombre map[string]*Ruta         // Ver Ruta.Nombrar() y g.URL().
+	rutasPorPatrón map[string]*Ruta         // Patrón del mux → ruta. Para preflight CORS.
+	cors           *PoliticaCORS            // Default para todas las rutas. Ver g.CORS().
+	corsGrupos     map[string]*PoliticaCORS // Prefijo → política del grupo. Para 404 en el grupo.
+
+	Comprimir bool // Comprimir respuestas de texto con gzip o deflate si el cliente lo acepta.
+
+	LimiteBody    int64 // Máximo de bytes del body de una solicitud. Cero para no limitar (default).
+	LimiteArchivo int64 // Máximo de bytes de cada archivo en un multipart. Cero para solo usar LimiteBody.
+
 	TmplBaseLayout string // Nombre de la plantilla base.
 	TmplError      string // Nombre de la plantilla para errores.
 
-	CleanupFunc func() // Ejecutar para un graceful shutdown.
+	CleanupFunc     func()        // Ejecutar para un graceful shutdown.
+	ShutdownTimeout time.Duration // Máximo para esperar solicitudes activas al apagar. Default 10s.
+	PuertoRedirHTTP int           // Con IniciarTLS, puerto HTTP que redirige a HTTPS. Cero para no usarlo.
+
+	terminar       chan struct{}  // Se cierra con g.Terminar() para apagar el servidor.
+	terminarMu     sync.Mutex     // Para crear el canal terminar.
+	terminarOnce   sync.Once      // Para cerrar el canal terminar.
+	apagando       chan struct{}  // Se cierra al iniciar el apagado para cortar streams abiertos.
+	apagandoOnce   sync.Once      // Para cerrar el canal apagando.
+	logsPendientes sync.WaitGroup // Logs http enviados al HTTPLogger sin terminar.
 }
 
 // Implementa la interfaz http.Handler.
@@ -67,83 +96,164 @@ func New() *Gecko {
 	return g
 }
 
-// Nuevo router cuando se registra una ruta en "GET /".
+// Igual que New(). Servía para registrar una ruta GET que atrape todo,
+// como "/{ruta...}", que chocaba con el handler para rutas no registradas.
+// Ahora ese handler está en "/" para cualquier método y no choca.
+//
+// Deprecated: usar New().
 func NewSinRoot404() *Gecko {
-	pwd, err := os.Getwd()
-	if err != nil {
-		gko.FatalError(err)
-	}
-	g := &Gecko{
-		mux: http.NewServeMux(),
-
-		Filesystem: os.DirFS(pwd),
-
-		TmplBaseLayout: "base_layout",
-		TmplError:      "",
-	}
-	return g
+	return New()
 }
 
 // Iniciar servidor HTTP: escuchar en puerto TCP.
+//
+// Bloquea hasta que el servidor termina por una señal del sistema o por
+// g.Terminar(), en cuyo caso retorna nil luego del graceful shutdown.
 func (g *Gecko) IniciarEnPuerto(port int) error {
 	if port < 1 || port > 65535 {
 		return gko.ErrDatoInvalido.Msg("puerto TCP inválido")
 	}
-	// Manejar terminación del servidor
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
-	go func() {
-		sig := <-signalChan // solamente manejar la primera señal y salir.
-		if g.CleanupFunc != nil {
-			g.CleanupFunc()
-		}
-		if g.HTTPLogger != nil {
-			g.HTTPLogger.Close()
-		}
-
-		fmt.Println("")
-		gko.LogInfof("Servidor terminado: %v", sig.String())
-		os.Exit(0)
-	}()
-	// Comenzar servidor
-	srv := http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: g,
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
 	}
 	gko.LogEventof("Escuchando en tcp/%d", port)
-	return srv.ListenAndServe()
+	return g.IniciarEnListener(ln)
 }
 
 // Iniciar servidor HTTP: escuchar en unix domain socket.
+//
+// Bloquea hasta que el servidor termina por una señal del sistema o por
+// g.Terminar(), en cuyo caso retorna nil luego del graceful shutdown.
 func (g *Gecko) IniciarEnSocket(socket string) error {
 	if socket == "" {
 		return gko.ErrDatoIndef.Msg("socket path indefinido")
 	}
-	// Manejar terminación del servidor
+	sock, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	gko.LogEventof("Escuchando en unix %v", socket)
+	err = g.IniciarEnListener(sock)
+	// El listener borra el socket al cerrarse, pero no si se interrumpió.
+	if errRm := os.Remove(socket); errRm != nil && !errors.Is(errRm, fs.ErrNotExist) {
+		gko.Op("Shutdown").Str("quitar socket file").Err(errRm).Log()
+	}
+	return err
+}
+
+// Iniciar servidor HTTP con un listener ya preparado.
+// Útil en pruebas con un puerto aleatorio: net.Listen("tcp", "127.0.0.1:0").
+//
+// Bloquea hasta que el servidor termina por una señal del sistema o por
+// g.Terminar(), en cuyo caso retorna nil luego del graceful shutdown.
+func (g *Gecko) IniciarEnListener(ln net.Listener) error {
+	srv := &http.Server{Handler: g}
+	return g.servir(srv, func() error { return srv.Serve(ln) }, nil)
+}
+
+// ================================================================ //
+// ========== GRACEFUL SHUTDOWN =================================== //
+
+// Tiempo que se espera a que terminen las solicitudes activas
+// al apagar el servidor si no se define ShutdownTimeout.
+const defaultShutdownTimeout = 10 * time.Second
+
+// Detiene el servidor iniciado con g.Iniciar... como si hubiera
+// recibido una señal de terminación. Se puede llamar varias veces.
+func (g *Gecko) Terminar() {
+	g.terminarOnce.Do(func() {
+		close(g.getTerminarChan())
+	})
+}
+
+func (g *Gecko) getTerminarChan() chan struct{} {
+	g.terminarMu.Lock()
+	defer g.terminarMu.Unlock()
+	if g.terminar == nil {
+		g.terminar = make(chan struct{})
+	}
+	return g.terminar
+}
+
+// Canal que se cierra cuando el servidor comienza a apagarse para que
+// las conexiones de larga duración (SSE) terminen y no retrasen el apagado.
+func (g *Gecko) getApagandoChan() chan struct{} {
+	g.terminarMu.Lock()
+	defer g.terminarMu.Unlock()
+	if g.apagando == nil {
+		g.apagando = make(chan struct{})
+	}
+	return g.apagando
+}
+
+// Ejecuta el servidor hasta que termine por error, señal o g.Terminar(),
+// y luego lo apaga ordenadamente:
+//
+//  1. Deja de aceptar conexiones y espera las solicitudes activas
+//     hasta ShutdownTimeout, después de lo cual se cortan.
+//  2. Ejecuta CleanupFunc.
+//  3. Espera los logs pendientes y cierra el HTTPLogger.
+//
+// Si se da la función recargar, SIGHUP la ejecuta en lugar de terminar.
+// Los servidores complementarios se apagan junto con srv.
+func (g *Gecko) servir(srv *http.Server, serve func() error, recargar func(), complementarios ...*http.Server) error {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+	defer signal.Stop(signalChan)
+
+	errChan := make(chan error, 1)
 	go func() {
-		sig := <-signalChan // solamente manejar la primera señal y salir.
-		if g.CleanupFunc != nil {
-			g.CleanupFunc()
-		}
-		if g.HTTPLogger != nil {
-			g.HTTPLogger.Close()
+		errChan <- serve()
+	}()
+
+	var motivo string
+	var errServe error
+	for motivo == "" {
+		select {
+		case sig := <-signalChan:
+			if sig == syscall.SIGHUP && recargar != nil {
+				recargar()
+				continue
+			}
+			motivo = sig.String() // solamente manejar la primera señal y salir.
+		case <-g.getTerminarChan():
+			motivo = "terminado por la aplicación"
+		case errServe = <-errChan:
+			motivo = "error: " + errServe.Error()
 		}
+	}
+
+	g.apagar(srv, complementarios...)
+	fmt.Println("")
+	gko.LogInfof("Servidor terminado: %v", motivo)
+	return errServe
+}
 
-		err := os.Remove(socket)
+// Apaga los servidores esperando a las solicitudes activas,
+// y luego ejecuta CleanupFunc y cierra HTTPLogger en ese orden.
+func (g *Gecko) apagar(srv *http.Server, complementarios ...*http.Server) {
+	timeout := g.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	g.apagandoOnce.Do(func() {
+		close(g.getApagandoChan())
+	})
+	for _, s := range append([]*http.Server{srv}, complementarios...) {
+		err := s.Shutdown(ctx)
 		if err != nil {
-			gko.Op("Shutdown").Str("quitar socket file").Err(err).Log()
+			gko.Op("Shutdown").Str("solicitudes activas interrumpidas").Err(err).Log()
+			s.Close()
 		}
-		fmt.Println("")
-		gko.LogInfof("Servidor terminado: %v", sig.String())
-		os.Exit(0)
-	}()
-	// Comenzar servidor
-	sock, err := net.Listen("unix", socket)
-	if err != nil {
-		return err
 	}
-	gko.LogEventof("Escuchando en unix %v", socket)
-	return http.Serve(sock, g)
+	if g.CleanupFunc != nil {
+		g.CleanupFunc()
+	}
+	if g.HTTPLogger != nil {
+		g.logsPendientes.Wait()
+		g.HTTPLogger.Close()
+	}
 }
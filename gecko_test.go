@@ -0,0 +1,94 @@
+package gecko
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type loggerPrueba struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	orden   *[]string
+}
+
+func (l *loggerPrueba) SaveLog(entry LogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *loggerPrueba) Close() {
+	*l.orden = append(*l.orden, "logger")
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	orden := []string{}
+	logger := &loggerPrueba{orden: &orden}
+
+	g := New()
+	g.HTTPLogger = logger
+	g.CleanupFunc = func() { orden = append(orden, "cleanup") }
+
+	enHandler := make(chan struct{})
+	g.GET("/lento", func(c *Context) error {
+		close(enHandler)
+		time.Sleep(200 * time.Millisecond)
+		return c.StringOk("listo")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errServidor := make(chan error, 1)
+	go func() {
+		errServidor <- g.IniciarEnListener(ln)
+	}()
+
+	type respuesta struct {
+		body string
+		err  error
+	}
+	resChan := make(chan respuesta, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String() + "/lento")
+		if err != nil {
+			resChan <- respuesta{err: err}
+			return
+		}
+		defer res.Body.Close()
+		b, err := io.ReadAll(res.Body)
+		resChan <- respuesta{body: string(b), err: err}
+	}()
+
+	<-enHandler
+	g.Terminar()
+
+	res := <-resChan
+	if res.err != nil {
+		t.Fatalf("solicitud activa interrumpida: %v", res.err)
+	}
+	if res.body != "listo" {
+		t.Errorf("body = %q, want %q", res.body, "listo")
+	}
+
+	select {
+	case err := <-errServidor:
+		if err != nil {
+			t.Errorf("IniciarEnListener() error = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("el servidor no terminó")
+	}
+
+	if len(orden) != 2 || orden[0] != "cleanup" || orden[1] != "logger" {
+		t.Errorf("orden de apagado = %v, want [cleanup logger]", orden)
+	}
+	if len(logger.entries) != 1 {
+		t.Errorf("logs guardados = %d, want 1", len(logger.entries))
+	}
+}
@@ -0,0 +1,149 @@
+package gecko
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== HTTPS =============================================== //
+
+// Iniciar servidor HTTPS: escuchar en puerto TCP con TLS.
+//
+// Si se define g.PuertoRedirHTTP también se escucha en ese puerto
+// para redirigir con 308 todas las solicitudes HTTP hacia HTTPS.
+//
+// Al recibir SIGHUP se vuelven a leer los certificados desde disco
+// sin cortar las conexiones activas, que conservan el anterior.
+// Si los nuevos certificados son inválidos se sigue usando el anterior.
+//
+// Bloquea hasta que el servidor termina por una señal del sistema o por
+// g.Terminar(), en cuyo caso retorna nil luego del graceful shutdown.
+func (g *Gecko) IniciarTLS(port int, certFile, keyFile string) error {
+	op := gko.Op("gecko.IniciarTLS")
+	if port < 1 || port > 65535 {
+		return op.E(gko.ErrDatoInvalido).Msg("puerto TCP inválido")
+	}
+	if certFile == "" || keyFile == "" {
+		return op.E(gko.ErrDatoIndef).Msg("certificado TLS indefinido")
+	}
+	cert := &certificadoTLS{certFile: certFile, keyFile: keyFile}
+	err := cert.cargar()
+	if err != nil {
+		return op.Err(err)
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return op.Err(err)
+	}
+	srv := &http.Server{
+		Handler: g,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: cert.getCertificate,
+		},
+	}
+
+	// Servidor complementario para redirigir HTTP a HTTPS.
+	// Se apaga junto con el principal.
+	var complementarios []*http.Server
+	if g.PuertoRedirHTTP > 0 {
+		redir, err := iniciarRedirHTTPS(g.PuertoRedirHTTP, port)
+		if err != nil {
+			ln.Close()
+			return op.Err(err)
+		}
+		complementarios = append(complementarios, redir)
+	}
+
+	gko.LogEventof("Escuchando en tcp/%d (TLS)", port)
+	return g.servir(srv,
+		func() error { return srv.ServeTLS(ln, "", "") },
+		func() {
+			err := cert.cargar()
+			if err != nil {
+				gko.Op("gecko.RecargarTLS").Err(err).Log()
+				return
+			}
+			gko.LogInfof("Certificados TLS recargados: %v", certFile)
+		},
+		complementarios...,
+	)
+}
+
+// ================================================================ //
+
+// Certificado TLS que se puede volver a leer desde disco
+// mientras el servidor atiende nuevas conexiones.
+type certificadoTLS struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// Lee el certificado y la llave y los pone en uso si son válidos.
+func (c *certificadoTLS) cargar() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return gko.ErrAlLeer.Err(err).Op("cargarCertificado").Ctx("cert", c.certFile)
+	}
+	c.cert.Store(&cert)
+	return nil
+}
+
+// Para tls.Config.GetCertificate en cada handshake.
+func (c *certificadoTLS) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// ================================================================ //
+// ========== REDIRECCIÓN HTTP -> HTTPS =========================== //
+
+// Inicia un servidor HTTP en el puerto dado que responde a toda solicitud
+// con "308 Permanent Redirect" hacia el mismo recurso con HTTPS.
+func iniciarRedirHTTPS(portHTTP int, portHTTPS int) (*http.Server, error) {
+	if portHTTP < 1 || portHTTP > 65535 {
+		return nil, gko.ErrDatoInvalido.Msg("puerto TCP inválido para redirigir HTTP")
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", portHTTP))
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{
+		Handler:           redirHTTPSHandler(portHTTPS),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		err := srv.Serve(ln)
+		if err != nil && err != http.ErrServerClosed {
+			gko.Op("gecko.RedirHTTPS").Err(err).Log()
+		}
+	}()
+	gko.LogEventof("Redirigiendo tcp/%d a HTTPS", portHTTP)
+	return srv, nil
+}
+
+func redirHTTPSHandler(portHTTPS int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			// Sin puerto. JoinHostPort pone los corchetes de IPv6.
+			host = strings.TrimSuffix(strings.TrimPrefix(r.Host, "["), "]")
+		}
+		if portHTTPS != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(portHTTPS))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		w.Header().Set(HeaderLocation, "https://"+host+r.URL.RequestURI())
+		w.WriteHeader(Status308PermanentRedirect)
+	}
+}
@@ -0,0 +1,114 @@
+package gecko
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Escribe un certificado autofirmado con el nombre dado.
+func escribirCertificado(t *testing.T, certFile, keyFile, nombre string) {
+	t.Helper()
+	llave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plantilla := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: nombre},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, plantilla, &llave.PublicKey, llave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	llaveDER, err := x509.MarshalECPrivateKey(llave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err == nil {
+		err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: llaveDER}), 0600)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nombreCertificado(t *testing.T, c *certificadoTLS) string {
+	t.Helper()
+	cert, err := c.getCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x.Subject.CommonName
+}
+
+func TestRecargarCertificado(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	escribirCertificado(t, certFile, keyFile, "primero")
+	c := &certificadoTLS{certFile: certFile, keyFile: keyFile}
+	if err := c.cargar(); err != nil {
+		t.Fatal(err)
+	}
+	if n := nombreCertificado(t, c); n != "primero" {
+		t.Fatalf("certificado %q", n)
+	}
+
+	escribirCertificado(t, certFile, keyFile, "segundo")
+	if err := c.cargar(); err != nil {
+		t.Fatal(err)
+	}
+	if n := nombreCertificado(t, c); n != "segundo" {
+		t.Errorf("después de recargar: %q", n)
+	}
+
+	// Un certificado inválido no reemplaza al que está en uso.
+	os.WriteFile(certFile, []byte("no es un certificado"), 0600)
+	if err := c.cargar(); err == nil {
+		t.Error("se esperaba error con certificado inválido")
+	}
+	if n := nombreCertificado(t, c); n != "segundo" {
+		t.Errorf("después de recarga fallida: %q", n)
+	}
+}
+
+func TestRedirHTTPS(t *testing.T) {
+	casos := []struct {
+		host   string
+		puerto int
+		url    string
+	}{
+		{"ejemplo.com", 443, "https://ejemplo.com/a?b=1"},
+		{"ejemplo.com:80", 443, "https://ejemplo.com/a?b=1"},
+		{"ejemplo.com:8080", 8443, "https://ejemplo.com:8443/a?b=1"},
+		{"[::1]", 443, "https://[::1]/a?b=1"},
+		{"[::1]", 8443, "https://[::1]:8443/a?b=1"},
+		{"[::1]:80", 8443, "https://[::1]:8443/a?b=1"},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(http.MethodPost, "/a?b=1", nil)
+		req.Host = caso.host
+		rec := httptest.NewRecorder()
+		redirHTTPSHandler(caso.puerto)(rec, req)
+		if rec.Code != http.StatusPermanentRedirect || rec.Header().Get(HeaderLocation) != caso.url {
+			t.Errorf("%s → %d: %d %q, se esperaba %q", caso.host, caso.puerto, rec.Code, rec.Header().Get(HeaderLocation), caso.url)
+		}
+	}
+}
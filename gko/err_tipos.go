@@ -13,7 +13,7 @@ func (e *Error) GetCodigoHTTP() int {
 	case e.Contiene(ErrTooManyReq):
 		return 429
 	case e.Contiene(ErrTooBig):
-		return 400
+		return 413
 	case e.Contiene(ErrTooLong):
 		return 400
 	case e.Contiene(ErrDatoIndef):
@@ -24,6 +24,12 @@ func (e *Error) GetCodigoHTTP() int {
 		return 415
 	case e.Contiene(ErrNoAutorizado):
 		return 403
+	case e.Contiene(ErrCSRF):
+		return 403
+	case e.Contiene(ErrNoPermitido):
+		return 405
+	case e.Contiene(ErrHandlerTimeout):
+		return 503
 	case e.Contiene(ErrTimeout):
 		return 408
 	case e.Contiene(ErrNoDisponible):
@@ -1,7 +1,10 @@
 package gko
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 )
 
@@ -49,6 +52,10 @@ type Error struct {
 
 	// Pares de claves=valor que dan contexto extra a la operación.
 	valores string
+
+	// Mensajes para el usuario por cada campo inválido de un formulario.
+	// La clave es el nombre del campo tal como se envía en la solicitud.
+	campos map[string]string
 }
 
 // ================================================================ //
@@ -58,6 +65,10 @@ type Error struct {
 // NUNCA retorna nil. Tampoco hace Wrap al error, por lo que errors.Is() ni
 // errors.As() funcionan con el error contenido. El gko.Error ofrece una
 // funcionalidad similar y extendia con sus métodos.
+//
+// Un context.Canceled o context.DeadlineExceeded, aunque venga envuelto,
+// siempre se convierte en ErrTimeout, por lo que también un cliente que
+// abandona la solicitud se responde y registra como timeout (408).
 func Err(err error) *Error {
 	// Si no hay error, retornar uno vacío.
 	if err == nil {
@@ -67,12 +78,24 @@ func Err(err error) *Error {
 	if errGk, ok := err.(*Error); ok {
 		return errGk
 	}
+	// Si es un ErrorKey usado directamente como error, conservar la clave.
+	if key, ok := err.(ErrorKey); ok {
+		return &Error{errKeys: []ErrorKey{key}}
+	}
+	// Si se canceló o venció un contexto, es un timeout.
+	if esErrContexto(err) {
+		return &Error{errKeys: []ErrorKey{ErrTimeout}, texto: err.Error()}
+	}
 	// Si es un error normal, transformarlo.
 	return &Error{
 		texto: err.Error(),
 	}
 }
 
+func esErrContexto(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
 // Crea un gko.Error a partir de un gko.ErrorKey y el error proporcionado.
 func (k ErrorKey) Err(err error) *Error {
 	e := &Error{errKeys: []ErrorKey{k}}
@@ -85,20 +108,29 @@ func (k ErrorKey) Err(err error) *Error {
 //	op := op.Copy().Op("InLoop").Strf("loop %v", i)
 func (e *Error) Copy() *Error {
 	newErr := *e
+	newErr.campos = maps.Clone(e.campos)
 	return &newErr
 }
 
 // Agregar error genérico a la cola, o combinar un *gko.Error de manera
 // adecuada, suponiendo que el error recibido viene de la capa de ejecución
 // inmediatamente inferior al error sobre el que se llama este método.
+// Igual que gko.Err agrega ErrTimeout si el error es de un contexto.
 func (e *Error) Err(err error) *Error {
 	// si no hay error nuevo, no hacer nada
 	if err == nil {
 		return e
 	}
+	// si es un ErrorKey solo agregar la clave.
+	if key, ok := err.(ErrorKey); ok {
+		return e.Key(key)
+	}
 	// si el error no es de gecko solo agregar el texto.
 	errGk, ok := err.(*Error)
 	if !ok {
+		if esErrContexto(err) {
+			e.Key(ErrTimeout)
+		}
 		e.Str(err.Error())
 		return e
 	}
@@ -122,6 +154,9 @@ func (e *Error) Err(err error) *Error {
 			e.valores += " " + errGk.valores
 		}
 	}
+	for campo, msg := range errGk.campos {
+		e.Campo(campo, msg)
+	}
 	return e
 }
 
@@ -143,23 +178,26 @@ type ErrorKey string
 // las capas más bajas de la aplicación (librerías externas) y pueda ser
 // identificado por las capas más altas (comandos de aplicación, handlers, UI).
 const (
-	ErrUserError    ErrorKey = "user_error"    // Error esperado provocado por el error.
-	ErrInesperado   ErrorKey = "inesperado"    // Error desconocido, normalmente de una dependencia externa.
-	ErrNoEncontrado ErrorKey = "not_found"     // No se encuentra un registro por su ID.
-	ErrYaExiste     ErrorKey = "ya_existe"     // Ya existe un recurson con el mismo ID.
-	ErrHayHuerfanos ErrorKey = "hay_huerfanos" // No se puede borrar porque tiene hijos.
-	ErrTooManyReq   ErrorKey = "too_many_req"  // Se esperaba un solo registro y se encontraron muchos.
-	ErrTooBig       ErrorKey = "too_big"       // Un archivo es demasiado pesado.
-	ErrTooLong      ErrorKey = "too_long"      // Un string es demasiado largo.
-	ErrDatoIndef    ErrorKey = "dato_indef"    // Un dato es obligatorio y no se recibió.
-	ErrDatoInvalido ErrorKey = "dato_invalido" // Un dato no cumple con las reglas de validación.
-	ErrNoSoportado  ErrorKey = "no_soportado"  // Un formato de archivo o dato no es soportado por el sistema.
-	ErrNoAutorizado ErrorKey = "no_autorizado" // Un usuario no tiene permisos para realizar una acción.
-	ErrTimeout      ErrorKey = "timeout"       // Una operación tarda más de lo esperado.
-	ErrNoDisponible ErrorKey = "no_disponible" // Un servicio no está disponible.
-	ErrNoSpaceLeft  ErrorKey = "no_space_left" // Se alcanzó la capacidad máxima.
-	ErrAlEscribir   ErrorKey = "al_escribir"   // Error al escribir en un archivo.
-	ErrAlLeer       ErrorKey = "al_leer"       // Error al leer un archivo.
+	ErrUserError      ErrorKey = "user_error"      // Error esperado provocado por el error.
+	ErrInesperado     ErrorKey = "inesperado"      // Error desconocido, normalmente de una dependencia externa.
+	ErrNoEncontrado   ErrorKey = "not_found"       // No se encuentra un registro por su ID.
+	ErrYaExiste       ErrorKey = "ya_existe"       // Ya existe un recurson con el mismo ID.
+	ErrHayHuerfanos   ErrorKey = "hay_huerfanos"   // No se puede borrar porque tiene hijos.
+	ErrTooManyReq     ErrorKey = "too_many_req"    // Se esperaba un solo registro y se encontraron muchos.
+	ErrTooBig         ErrorKey = "too_big"         // Un archivo es demasiado pesado.
+	ErrTooLong        ErrorKey = "too_long"        // Un string es demasiado largo.
+	ErrDatoIndef      ErrorKey = "dato_indef"      // Un dato es obligatorio y no se recibió.
+	ErrDatoInvalido   ErrorKey = "dato_invalido"   // Un dato no cumple con las reglas de validación.
+	ErrNoSoportado    ErrorKey = "no_soportado"    // Un formato de archivo o dato no es soportado por el sistema.
+	ErrNoAutorizado   ErrorKey = "no_autorizado"   // Un usuario no tiene permisos para realizar una acción.
+	ErrNoPermitido    ErrorKey = "no_permitido"    // La acción existe pero no con el método solicitado.
+	ErrCSRF           ErrorKey = "csrf"            // Una solicitud no trae el token CSRF válido.
+	ErrTimeout        ErrorKey = "timeout"         // Una operación tarda más de lo esperado.
+	ErrHandlerTimeout ErrorKey = "handler_timeout" // El servidor no terminó de atender la solicitud a tiempo.
+	ErrNoDisponible   ErrorKey = "no_disponible"   // Un servicio no está disponible.
+	ErrNoSpaceLeft    ErrorKey = "no_space_left"   // Se alcanzó la capacidad máxima.
+	ErrAlEscribir     ErrorKey = "al_escribir"     // Error al escribir en un archivo.
+	ErrAlLeer         ErrorKey = "al_leer"         // Error al leer un archivo.
 )
 
 // ErrorKey implementa la interfaz error
@@ -255,6 +293,43 @@ func (e *Error) Msgf(format string, a ...any) *Error {
 	return e
 }
 
+// ================================================================ //
+// ========== Al usuario - Campos ================================= //
+
+// Asocia un mensaje para el usuario a un campo del formulario para que
+// pueda mostrarse junto a él. Si el campo ya tenía mensaje se reemplaza.
+//
+//	return gko.ErrDatoInvalido.Msg("Revisa los datos").
+//	    Campo("email", "El correo no es válido").
+//	    Campo("edad", "Debes ser mayor de edad")
+func (e *Error) Campo(campo, msg string) *Error {
+	if campo == "" || msg == "" {
+		LogWarn("err.Campo() con campo o mensaje vacío")
+		return e
+	}
+	if e.campos == nil {
+		e.campos = map[string]string{}
+	}
+	e.campos[campo] = msg
+	return e
+}
+
+// Crea un nuevo gko.Error con un mensaje para el usuario sobre un campo.
+func (k ErrorKey) Campo(campo, msg string) *Error {
+	e := &Error{errKeys: []ErrorKey{k}}
+	return e.Campo(campo, msg)
+}
+
+// Devuelve una copia de los mensajes por campo. Nil si no hay.
+func (e *Error) GetCampos() map[string]string {
+	return maps.Clone(e.campos)
+}
+
+// Reporta si el error tiene mensajes asociados a campos de formulario.
+func (e *Error) TieneCampos() bool {
+	return len(e.campos) > 0
+}
+
 // ================================================================ //
 // ========== Al desarrollador ==================================== //
 
@@ -0,0 +1,36 @@
+package gko
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCampos(t *testing.T) {
+	err := ErrDatoInvalido.Campo("email", "El correo no es válido").
+		Campo("edad", "Debes ser mayor de edad").
+		Campo("edad", "Falta la edad").
+		Campo("", "sin campo").
+		Campo("nombre", "")
+	campos := err.GetCampos()
+	if len(campos) != 2 || campos["email"] != "El correo no es válido" || campos["edad"] != "Falta la edad" {
+		t.Errorf("campos: %v", campos)
+	}
+	if !err.TieneCampos() || !Is(err, ErrDatoInvalido) {
+		t.Errorf("error sin campos o sin clave: %v", err)
+	}
+
+	// GetCampos da una copia.
+	campos["email"] = "otro"
+	if err.GetCampos()["email"] != "El correo no es válido" {
+		t.Error("modificar el mapa de GetCampos cambió el error")
+	}
+
+	// Se conservan al envolver el error.
+	envuelto := Op("Guardar").Err(err)
+	if envuelto.GetCampos()["edad"] != "Falta la edad" || Err(error(err)).GetCampos()["email"] == "" {
+		t.Errorf("campos al envolver: %v", envuelto.GetCampos())
+	}
+	if Err(errors.New("otro")).TieneCampos() || Err(nil).GetCampos() != nil {
+		t.Error("error sin campos reporta campos")
+	}
+}
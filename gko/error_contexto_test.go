@@ -0,0 +1,32 @@
+package gko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrContexto(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		err     error
+		timeout bool
+	}{
+		{"cancelado", context.Canceled, true},
+		{"vencido", context.DeadlineExceeded, true},
+		{"envuelto", fmt.Errorf("consultar: %w", context.Canceled), true},
+		{"otro", errors.New("sql: no rows in result set"), false},
+	}
+	for _, caso := range casos {
+		if got := Is(Err(caso.err), ErrTimeout); got != caso.timeout {
+			t.Errorf("%s: gko.Err es ErrTimeout = %v", caso.nombre, got)
+		}
+		if got := Is(ErrAlLeer.Err(caso.err), ErrTimeout); got != caso.timeout {
+			t.Errorf("%s: ErrAlLeer.Err es ErrTimeout = %v", caso.nombre, got)
+		}
+	}
+	if err := Err(context.Canceled); err.GetCodigoHTTP() != 408 {
+		t.Errorf("código HTTP %d", err.GetCodigoHTTP())
+	}
+}
@@ -66,15 +66,23 @@ func (e Event) Mensaje() string {
 // ========== Event Store ========================================= //
 
 type EventStore struct {
-	Repo       EventStoreRepo // Persisitir eventos.
-	Results    *TxResult      // Guardar en memoria durante la transacción.
-	ConsoleLog bool           // Activar para mostrar mensajes en log.
+	Repo        EventStoreRepo   // Persisitir eventos.
+	Results     *TxResult        // Guardar en memoria durante la transacción.
+	ConsoleLog  bool             // Activar para mostrar mensajes en log.
+	Broadcaster EventBroadcaster // Notificar eventos a clientes conectados (ej. SSE).
 }
 
 type EventStoreRepo interface {
 	Guardar(ev RawEventRow) error
 }
 
+// EventBroadcaster recibe los eventos registrados para notificarlos
+// en vivo, por ejemplo a navegadores conectados con gecko.HubSSE.
+// No debe bloquear.
+type EventBroadcaster interface {
+	Broadcast(ev Event)
+}
+
 // Registra un evento en los lugares configurados (Repo / TxResults / Log)
 //
 // Key: identificador del evento. Ej. "usuario_registrado".
@@ -126,8 +134,13 @@ func (s *EventStore) Rise(responsableID gkoid.Decimal, key EventKey, data EventD
 		s.Results.Events = append(s.Results.Events, ev)
 	}
 
+	// Notificar a clientes conectados.
+	if s.Broadcaster != nil {
+		s.Broadcaster.Broadcast(ev)
+	}
+
 	// Log como fallback o si se configuró.
-	if (s.Results == nil && s.Repo == nil) || s.ConsoleLog {
+	if (s.Results == nil && s.Repo == nil && s.Broadcaster == nil) || s.ConsoleLog {
 		LogInfof("%v %+v", key, data.ToMsg(""))
 	}
 
@@ -137,8 +137,9 @@ func (e *Error) Print() {
 		"\n\tops: "+cPurple+"%s"+reset+
 		"\n\tctx: "+cPurple+"%s"+reset+
 		"\n\ttxt: "+cPurple+"%v"+reset+
+		"\n\tcampos: "+cPurple+"%v"+reset+
 		"\n}\n",
-		e.errKeys, e.mensaje, e.operación, e.valores, e.texto,
+		e.errKeys, e.mensaje, e.operación, e.valores, e.texto, e.campos,
 	)
 }
 
@@ -5,6 +5,20 @@ htmx.defineExtension('gecko', {
 		// Devuelve el valor del atributo o null aplicando herencia.
 		this.getClosestAttributeValue = api.getClosestAttributeValue;
 	},
+	// Token CSRF del formulario del elemento o el del documento.
+	getCSRFToken: function (elt) {
+		const form = elt.closest ? elt.closest("form") : null;
+		const input = form ? form.querySelector('input[name="csrf_token"]') : null;
+		if (input && input.value) {
+			return input.value;
+		}
+		const meta = document.querySelector('meta[name="csrf-token"]');
+		if (meta && meta.content) {
+			return meta.content;
+		}
+		const anyInput = document.querySelector('input[name="csrf_token"]');
+		return anyInput ? anyInput.value : null;
+	},
     onEvent: function (name, event) {
         
 		// Codificar respuesta al prompt antes de enviarla en header.
@@ -22,6 +36,19 @@ htmx.defineExtension('gecko', {
 			if (askforVal && askforVal.length > 0) {
 				event.detail.headers["Hx-Askfor"] = askforVal;
 			}
+
+			// CSRF: enviar el token para que el servidor acepte la solicitud.
+			const csrfToken = this.getCSRFToken(event.target)
+			if (csrfToken) {
+				event.detail.headers["X-CSRF-Token"] = csrfToken;
+			}
+		}
+
+		// 422: el servidor devuelve el formulario con los errores de validación.
+		// htmx no hace swap de respuestas 4xx por defecto.
+		if (name === 'htmx:beforeSwap' && event.detail.xhr.status === 422) {
+			event.detail.shouldSwap = true;
+			event.detail.isError = false;
 		}
     }
 });
@@ -9,6 +9,7 @@ import (
 // Recibe un JSON del request y pone los datos en v con json.Unmarshal
 func (c *Context) JSONUnmarshal(v any) error {
 	err := json.NewDecoder(c.request.Body).Decode(v)
+	err = errorBody(err)
 	if ute, ok := err.(*json.UnmarshalTypeError); ok {
 		return gko.Err(err).Strf("Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", ute.Type, ute.Value, ute.Field, ute.Offset)
 	} else if se, ok := err.(*json.SyntaxError); ok {
@@ -87,6 +88,8 @@ func (c *Context) JSONBlob(code int, b []byte) (err error) {
 // Utiliza json.Marshal y puede no ser eficiente para grandes objetos.
 //
 // Ejemplo: miFuncion({x=1,y=2});
+//
+// Deprecated: usar c.JSON con una PoliticaCORS para otros orígenes.
 func (c *Context) JSONP(code int, callback string, i interface{}) (err error) {
 	return c.jsonPBlob(code, callback, i)
 }
@@ -65,7 +65,12 @@ func (g *Gecko) logHTTP(c *Context, err error) {
 	if len(c.SesionID) > 6 {
 		logEnt.Sesion = c.SesionID[:6] // Conocer usuario sin exponer sesión.
 	}
-	go g.HTTPLogger.SaveLog(logEnt) // Async para no retener al cliente.
+	// Async para no retener al cliente, pero esperado al apagar el servidor.
+	g.logsPendientes.Add(1)
+	go func() {
+		defer g.logsPendientes.Done()
+		g.HTTPLogger.SaveLog(logEnt)
+	}()
 }
 
 // ================================================================ //
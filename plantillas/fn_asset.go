@@ -0,0 +1,34 @@
+package plantillas
+
+import (
+	"html/template"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// Resuelve la ruta de un archivo estático a su URL con huella.
+// Lo implementa *gecko.Assets.
+type ResolverAssets interface {
+	URL(ruta string) string
+}
+
+// Función "asset" usada mientras no se configure un ResolverAssets.
+// Devuelve la ruta tal cual para que las plantillas sigan funcionando.
+func assetSinResolver(ruta string) string {
+	gko.LogWarnf("plantillas.asset: sin assets configurados para '%s', ver UsarAssets", ruta)
+	return ruta
+}
+
+// Configura la función "asset" para obtener la URL con huella:
+//
+//	<link rel="stylesheet" href="{{ asset "css/app.css" }}">
+func (s *TemplateResponder) UsarAssets(assets ResolverAssets) {
+	s.agregarFuncs(template.FuncMap{"asset": assets.URL})
+}
+
+// Configura la función "asset" para obtener la URL con huella:
+//
+//	<link rel="stylesheet" href="{{ asset "css/app.css" }}">
+func (s *TemplateResponderFS) UsarAssets(assets ResolverAssets) {
+	s.agregarFuncs(template.FuncMap{"asset": assets.URL})
+}
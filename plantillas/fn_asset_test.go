@@ -0,0 +1,56 @@
+package plantillas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pargomx/gecko"
+)
+
+const plantillaAsset = `<link rel="stylesheet" href="{{ asset "app.css" }}">`
+
+// La función "asset" usa la URL con huella en ambos servicios,
+// también después de volver a leer las plantillas.
+func TestUsarAssets(t *testing.T) {
+	assets, err := gecko.NuevoAssets("/static", fstest.MapFS{"app.css": {Data: []byte("body {}")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := assets.URL("app.css")
+	if url == "/static/app.css" {
+		t.Fatalf("URL sin huella: %q", url)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pagina.html"), []byte(plantillaAsset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpls, err := NuevoServicioPlantillas(dir, true) // Con reparse en cada Render.
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpls.UsarAssets(assets)
+
+	tmplsFS, err := NuevoServicioPlantillasEmbebidas(fstest.MapFS{
+		"plantillas/pagina.html": {Data: []byte(plantillaAsset)},
+	}, "plantillas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmplsFS.UsarAssets(assets)
+
+	for nombre, renderer := range map[string]gecko.Renderer{"carpeta": tmpls, "embebidas": tmplsFS} {
+		for range 2 {
+			var b strings.Builder
+			if err := renderer.Render(&b, "pagina", nil, nil); err != nil {
+				t.Fatalf("%s: %v", nombre, err)
+			}
+			if !strings.Contains(b.String(), `href="`+url+`"`) {
+				t.Errorf("%s: %q", nombre, b.String())
+			}
+		}
+	}
+}
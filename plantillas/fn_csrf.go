@@ -0,0 +1,23 @@
+package plantillas
+
+import (
+	"html/template"
+
+	"github.com/pargomx/gecko"
+)
+
+// Input hidden con el token CSRF para formularios. En los multipart
+// debe ser el primer campo.
+//
+//	<form method="post">{{ csrfInput .CSRFToken }} ... </form>
+func csrfInput(token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + gecko.CampoCSRF + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
+
+// Meta tag con el token CSRF para ponerlo en el <head> del layout
+// y que gecko.js lo envíe en las solicitudes HTMX fuera de formularios.
+//
+//	<head>{{ csrfMeta .CSRFToken }}</head>
+func csrfMeta(token string) template.HTML {
+	return template.HTML(`<meta name="csrf-token" content="` + template.HTMLEscapeString(token) + `">`)
+}
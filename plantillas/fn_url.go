@@ -2,7 +2,11 @@ package plantillas
 
 import (
 	"fmt"
+	"html/template"
 	"net/url"
+	"text/template/parse"
+
+	"github.com/pargomx/gecko/gko"
 )
 
 // addQueryParam agrega un query param a la URL.
@@ -26,3 +30,132 @@ func addQueryNum(URL *url.URL, key string, value interface{}) string {
 	URL.RawQuery = q.Encode()
 	return URL.String()
 }
+
+// ================================================================ //
+
+// Construye URLs de rutas por su nombre. Lo implementa *gecko.Gecko.
+type ResolverRutas interface {
+	URL(nombre string, params ...any) (string, error)
+}
+
+// Función "url" usada mientras no se configure un ResolverRutas.
+func urlSinResolver(nombre string, params ...any) (string, error) {
+	return "", gko.ErrNoDisponible.Strf("plantillas.url: sin rutas configuradas para '%s', ver UsarRutas", nombre)
+}
+
+// Configura la función "url" para construir URLs por nombre de ruta.
+// Si la ruta no existe o faltan parámetros falla la ejecución de la plantilla,
+// por lo que conviene revisarlas al iniciar con ValidarURLs.
+//
+//	<a href="{{ url "usuario.editar" .UsuarioID }}">Editar</a>
+func (s *TemplateResponder) UsarRutas(rutas ResolverRutas) {
+	s.agregarFuncs(template.FuncMap{"url": rutas.URL})
+}
+
+// Configura la función "url" para construir URLs por nombre de ruta.
+// Si la ruta no existe o faltan parámetros falla la ejecución de la plantilla,
+// por lo que conviene revisarlas al iniciar con ValidarURLs.
+//
+//	<a href="{{ url "usuario.editar" .UsuarioID }}">Editar</a>
+func (s *TemplateResponderFS) UsarRutas(rutas ResolverRutas) {
+	s.agregarFuncs(template.FuncMap{"url": rutas.URL})
+}
+
+// Revisa que cada {{ url "nombre" ... }} de las plantillas use una ruta
+// registrada con el número correcto de parámetros, para fallar al iniciar
+// la aplicación y no hasta que se ejecute la plantilla. Llamar después de
+// registrar todas las rutas.
+//
+//	tmpls.UsarRutas(g)
+//	if err := tmpls.ValidarURLs(g); err != nil {
+//		gko.FatalError(err)
+//	}
+//
+// Solo se revisan las llamadas con el nombre de la ruta literal.
+func (s *TemplateResponder) ValidarURLs(rutas ResolverRutas) error {
+	return validarURLs(s.t, rutas)
+}
+
+// Revisa que cada {{ url "nombre" ... }} de las plantillas use una ruta
+// registrada con el número correcto de parámetros. Ver TemplateResponder.ValidarURLs.
+func (s *TemplateResponderFS) ValidarURLs(rutas ResolverRutas) error {
+	return validarURLs(s.t, rutas)
+}
+
+func validarURLs(t *template.Template, rutas ResolverRutas) error {
+	op := gko.Op("plantillas.ValidarURLs")
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+			continue
+		}
+		var errURL error
+		recorrerNodos(tmpl.Tree.Root, func(cmd *parse.CommandNode, encadenado bool) {
+			if errURL != nil || len(cmd.Args) < 2 {
+				return
+			}
+			if fn, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || fn.Ident != "url" {
+				return
+			}
+			nombre, ok := cmd.Args[1].(*parse.StringNode)
+			if !ok {
+				return
+			}
+			// Los valores no importan, solo cuántos son.
+			params := make([]any, len(cmd.Args)-2)
+			if encadenado {
+				params = append(params, "x")
+			}
+			for i := range params {
+				params[i] = "x"
+			}
+			if _, err := rutas.URL(nombre.Text, params...); err != nil {
+				ubicación, _ := tmpl.Tree.ErrorContext(cmd)
+				errURL = op.Err(err).Ctx("plantilla", ubicación)
+			}
+		})
+		if errURL != nil {
+			return errURL
+		}
+	}
+	return nil
+}
+
+// Llama fn con cada comando del árbol de la plantilla. Encadenado indica
+// que recibe como último argumento el resultado del comando anterior.
+func recorrerNodos(nodo parse.Node, fn func(cmd *parse.CommandNode, encadenado bool)) {
+	switch n := nodo.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, hijo := range n.Nodes {
+			recorrerNodos(hijo, fn)
+		}
+	case *parse.ActionNode:
+		recorrerNodos(n.Pipe, fn)
+	case *parse.TemplateNode:
+		recorrerNodos(n.Pipe, fn)
+	case *parse.IfNode:
+		recorrerRama(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		recorrerRama(&n.BranchNode, fn)
+	case *parse.WithNode:
+		recorrerRama(&n.BranchNode, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			fn(cmd, i > 0)
+			for _, arg := range cmd.Args {
+				recorrerNodos(arg, fn)
+			}
+		}
+	}
+}
+
+func recorrerRama(n *parse.BranchNode, fn func(cmd *parse.CommandNode, encadenado bool)) {
+	recorrerNodos(n.Pipe, fn)
+	recorrerNodos(n.List, fn)
+	recorrerNodos(n.ElseList, fn)
+}
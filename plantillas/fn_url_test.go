@@ -0,0 +1,62 @@
+package plantillas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pargomx/gecko"
+)
+
+func TestValidarURLs(t *testing.T) {
+	h := func(c *gecko.Context) error { return nil }
+	g := gecko.New()
+	g.GET("/", h).Nombrar("inicio")
+	g.GET("/usuarios/{id}", h).Nombrar("usuario")
+	g.GET("/archivos/{ruta...}", h).Nombrar("archivo")
+
+	válida := `<a href="{{ url "usuario" .ID }}">{{ .ID | url "usuario" }}</a>
+		{{ url "inicio" }} {{ url "archivo" "a/b.txt" }} {{ url .Dinamica }}
+		{{ printf "%s" (url "usuario" 1) }}`
+	casos := []struct {
+		nombre    string
+		plantilla string
+		err       string // Vacío si es válida.
+	}{
+		{"válida", válida, ""},
+		{"falta parámetro", `<a href="{{ url "usuario" }}">`, "falta parámetro {id}"},
+		{"ruta desconocida", `{{ url "no.existe" 1 }}`, "ruta 'no.existe' no registrada"},
+		{"parámetro de más", `{{ url "inicio" 1 }}`, "1 parámetros de más"},
+		{"encadenado de más", `{{ .ID | url "inicio" }}`, "1 parámetros de más"},
+		{"dentro de bloques", `{{ if .A }}{{ else }}{{ range .B }}{{ with .C }}{{ url "usuario" }}{{ end }}{{ end }}{{ end }}`, "falta parámetro"},
+		{"como argumento", `{{ printf "%s" (url "archivo") }}`, "falta parámetro {ruta...}"},
+		{"en define", `{{ define "enlace" }}{{ url "usuario" 1 2 }}{{ end }}`, "parámetros de más"},
+	}
+	for _, caso := range casos {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "pagina.html"), []byte(caso.plantilla), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tmpls, err := NuevoServicioPlantillas(dir, false)
+		if err != nil {
+			t.Fatalf("%s: %v", caso.nombre, err)
+		}
+		tmpls.UsarRutas(g)
+		tmplsFS, err := NuevoServicioPlantillasEmbebidas(fstest.MapFS{"pagina.html": {Data: []byte(caso.plantilla)}}, "")
+		if err != nil {
+			t.Fatalf("%s: %v", caso.nombre, err)
+		}
+		tmplsFS.UsarRutas(g)
+
+		for servicio, err := range map[string]error{"carpeta": tmpls.ValidarURLs(g), "embebidas": tmplsFS.ValidarURLs(g)} {
+			if caso.err == "" && err != nil {
+				t.Errorf("%s %s: %v", caso.nombre, servicio, err)
+			}
+			if caso.err != "" && (err == nil || !strings.Contains(err.Error(), caso.err)) {
+				t.Errorf("%s %s: error %v, se esperaba %q", caso.nombre, servicio, err, caso.err)
+			}
+		}
+	}
+}
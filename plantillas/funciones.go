@@ -81,8 +81,13 @@ var funcMap = template.FuncMap{
 	// * HTML
 	"enfatizar": Enfatizar,
 
+	// * SEGURIDAD
+	"csrfInput": csrfInput,
+	"csrfMeta":  csrfMeta,
+
 	// * ARCHIVOS
 	"filesize": ByteCountSI,
+	"asset":    assetSinResolver, // Ver UsarAssets.
 
 	// * STRINGS
 	"concat": func(args ...any) string {
@@ -126,4 +131,5 @@ var funcMap = template.FuncMap{
 
 	"addQueryParam": addQueryParam,
 	"addQueryNum":   addQueryNum,
+	"url":           urlSinResolver, // Ver UsarRutas.
 }
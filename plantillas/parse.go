@@ -93,6 +93,9 @@ func (s *TemplateResponder) ReParse() {
 		gko.LogWarnf("plantillas.ReParse: usando plantillas anteriores: %v", err)
 		return
 	}
+	if s.funcsExtra != nil {
+		newTmpl.Funcs(s.funcsExtra)
+	}
 	s.t = newTmpl
 }
 
@@ -25,6 +25,10 @@ type TemplateResponder struct {
 	// Volver a leer plantillas antes de ejecutarlas.
 	// Utilizar solamente durante el desarrollo.
 	reparse bool
+
+	// Funciones configuradas después de crear el servicio que
+	// reemplazan a las de funcMap, como "asset".
+	funcsExtra template.FuncMap
 }
 
 // NuevoServicioPlantillas prepara todas las plantillas dentro
@@ -77,6 +81,20 @@ func NuevoServicioPlantillas(carpeta string, reparse bool) (*TemplateResponder,
 
 // ================================================================ //
 
+// Reemplaza funciones de funcMap en las plantillas ya preparadas
+// y en las que se vuelvan a leer con ReParse.
+func (s *TemplateResponder) agregarFuncs(funcs template.FuncMap) {
+	if s.funcsExtra == nil {
+		s.funcsExtra = template.FuncMap{}
+	}
+	for nombre, fn := range funcs {
+		s.funcsExtra[nombre] = fn
+	}
+	s.t.Funcs(funcs)
+}
+
+// ================================================================ //
+
 // Lookup returns the template with the given name that is
 // associated with t, or nil if there is no such template.
 func (s *TemplateResponder) Lookup(nombre string) *template.Template {
@@ -19,6 +19,10 @@ type TemplateResponderFS struct {
 	// un nombre único. Las plantillas pueden o no estar
 	// asociadas entre sí (anidadas unas en otras).
 	t *template.Template
+
+	// Funciones configuradas después de crear el servicio que
+	// reemplazan a las de funcMap, como "asset".
+	funcsExtra template.FuncMap
 }
 
 // Prepara todas las plantillas encontradas en el filesystem dado.
@@ -79,6 +83,17 @@ func NuevoServicioPlantillasEmbebidas(fsys fs.FS, tplsDir string) (*TemplateResp
 	return s, nil
 }
 
+// Reemplaza funciones de funcMap en las plantillas ya preparadas.
+func (s *TemplateResponderFS) agregarFuncs(funcs template.FuncMap) {
+	if s.funcsExtra == nil {
+		s.funcsExtra = template.FuncMap{}
+	}
+	for nombre, fn := range funcs {
+		s.funcsExtra[nombre] = fn
+	}
+	s.t.Funcs(funcs)
+}
+
 // ================================================================ //
 // ========== RENDER ============================================== //
 
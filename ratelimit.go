@@ -0,0 +1,188 @@
+package gecko
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== LÍMITE DE SOLICITUDES =============================== //
+
+// Limita las solicitudes por cliente con un token bucket: cada cliente
+// puede hacer hasta Limite solicitudes de golpe y recupera Limite
+// solicitudes por cada Periodo. Al excederlo responde gko.ErrTooManyReq.
+//
+// Cada ruta o grupo que use un limitador distinto tiene su propia cuenta.
+//
+//	login := gecko.NuevoLimitadorSolicitudes(5, time.Minute)
+//	g.POS("/login", s.postLogin, login.Middleware)
+//
+//	api := gecko.NuevoLimitadorSolicitudes(100, time.Minute)
+//	api.Clave = gecko.ClavePorSesion
+//	api.Confiar(gecko.TrustPrivateNet(false))
+//	gr := g.Group("/api", api.Middleware)
+type LimitadorSolicitudes struct {
+	Limite  int           // Capacidad del bucket.
+	Periodo time.Duration // Tiempo en que se recupera el bucket completo.
+
+	// Identifica al cliente. Default: gecko.ClavePorIP.
+	Clave func(c *Context) string
+
+	// Máximo de clientes con cuenta a la vez para que la memoria no crezca
+	// sin límite. Al llenarse se descarta la cuenta de algún otro cliente.
+	// Default 100,000.
+	MaxClientes int
+
+	confiables *ipChecker // IPs que no se limitan. Ver l.Confiar().
+
+	mu          sync.Mutex
+	buckets     map[string]*bucketSolicitudes
+	últimaPurga time.Time
+}
+
+type bucketSolicitudes struct {
+	tokens float64
+	último time.Time // Última vez que se recargó.
+}
+
+func NuevoLimitadorSolicitudes(limite int, periodo time.Duration) *LimitadorSolicitudes {
+	if limite <= 0 || periodo <= 0 {
+		gko.FatalExitf("gecko.LimitadorSolicitudes: límite y periodo deben ser positivos")
+	}
+	return &LimitadorSolicitudes{
+		Limite:  limite,
+		Periodo: periodo,
+		buckets: map[string]*bucketSolicitudes{},
+	}
+}
+
+const maxClientesDefault = 100_000
+
+// Identifica al cliente por su IP según g.IPExtractor. Si no hay uno
+// configurado se usa la IP de la conexión y se ignoran los headers
+// X-Forwarded-For y X-Real-IP, porque el cliente los puede inventar
+// para tener una cuenta nueva en cada solicitud. Detrás de un proxy
+// se debe configurar g.IPExtractor.
+func ClavePorIP(c *Context) string {
+	if c.gecko != nil && c.gecko.IPExtractor != nil {
+		return c.RealIP()
+	}
+	return extractIP(c.request)
+}
+
+// Identifica al cliente por su sesión, o por su IP si no tiene.
+func ClavePorSesion(c *Context) string {
+	if c.SesionID != "" {
+		return "s:" + c.SesionID
+	}
+	return ClavePorIP(c)
+}
+
+// Las IPs que cumplan las opciones no se limitan. Como en el IPExtractor,
+// por default se confía en loopback, link-local y redes privadas a menos
+// que se desactiven con TrustLoopback(false), etc.
+//
+// La IP se obtiene igual que en ClavePorIP.
+func (l *LimitadorSolicitudes) Confiar(opts ...TrustOption) {
+	l.confiables = newIPChecker(opts)
+}
+
+// Middleware que aplica el límite a las rutas donde se use.
+func (l *LimitadorSolicitudes) Middleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if l.confiables != nil {
+			if ip := net.ParseIP(ClavePorIP(c)); ip != nil && l.confiables.trust(ip) {
+				return next(c)
+			}
+		}
+		clave := ClavePorIP(c)
+		if l.Clave != nil {
+			clave = l.Clave(c)
+		}
+		permitida, restantes, espera, reinicio := l.tomar(clave, time.Now())
+
+		h := c.response.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(l.Limite))
+		h.Set("RateLimit-Remaining", strconv.Itoa(restantes))
+		h.Set("RateLimit-Reset", segundosHeader(reinicio))
+		if !permitida {
+			h.Set("Retry-After", segundosHeader(espera))
+			return gko.ErrTooManyReq.Msg("Demasiadas solicitudes, intenta de nuevo en unos momentos").
+				Op("LimitadorSolicitudes").Ctx("espera", espera.Round(time.Second))
+		}
+		return next(c)
+	}
+}
+
+// Consume un token del cliente si tiene. Retorna los tokens restantes,
+// cuánto falta para tener uno y cuánto para tener el bucket lleno.
+func (l *LimitadorSolicitudes) tomar(clave string, ahora time.Time) (permitida bool, restantes int, espera, reinicio time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = map[string]*bucketSolicitudes{}
+	}
+	l.purgarInactivos(ahora)
+
+	capacidad := float64(l.Limite)
+	porToken := l.Periodo / time.Duration(l.Limite)
+
+	b, ok := l.buckets[clave]
+	if !ok {
+		l.liberarEspacio()
+		b = &bucketSolicitudes{tokens: capacidad, último: ahora}
+		l.buckets[clave] = b
+	} else {
+		recuperados := float64(ahora.Sub(b.último)) / float64(porToken)
+		b.tokens = math.Min(capacidad, b.tokens+recuperados)
+		b.último = ahora
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		permitida = true
+	} else {
+		espera = time.Duration((1 - b.tokens) * float64(porToken))
+	}
+	reinicio = time.Duration((capacidad - b.tokens) * float64(porToken))
+	return permitida, int(b.tokens), espera, reinicio
+}
+
+// Elimina los buckets que ya se habrían recuperado por completo, pues
+// equivalen a uno nuevo. Así la memoria solo crece con los clientes
+// activos en el último periodo. Se ejecuta a lo mucho una vez por periodo.
+func (l *LimitadorSolicitudes) purgarInactivos(ahora time.Time) {
+	if ahora.Sub(l.últimaPurga) < l.Periodo {
+		return
+	}
+	l.últimaPurga = ahora
+	for clave, b := range l.buckets {
+		if ahora.Sub(b.último) >= l.Periodo {
+			delete(l.buckets, clave)
+		}
+	}
+}
+
+// Si ya se llegó a MaxClientes descarta alguna cuenta al azar para
+// agregar otra. Un cliente descartado solo recupera su bucket completo.
+func (l *LimitadorSolicitudes) liberarEspacio() {
+	máximo := l.MaxClientes
+	if máximo <= 0 {
+		máximo = maxClientesDefault
+	}
+	for clave := range l.buckets {
+		if len(l.buckets) < máximo {
+			return
+		}
+		delete(l.buckets, clave)
+	}
+}
+
+// Segundos redondeados hacia arriba para los headers.
+func segundosHeader(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
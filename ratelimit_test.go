@@ -0,0 +1,63 @@
+package gecko
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimitadorIgnoraXFFFalso(t *testing.T) {
+	limitador := NuevoLimitadorSolicitudes(2, time.Minute)
+	limitador.Confiar()
+	g := New()
+	g.GET("/", func(c *Context) error { return c.StringOk("ok") }, limitador.Middleware)
+
+	solicitud := func(xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.5:40000"
+		req.Header.Set(HeaderXForwardedFor, xff)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	// Un XFF de loopback no lo vuelve confiable.
+	for i := range 3 {
+		if status := solicitud("127.0.0.1"); i < 2 && status != http.StatusOK || i == 2 && status != http.StatusTooManyRequests {
+			t.Fatalf("solicitud %d con XFF loopback: status %d", i, status)
+		}
+	}
+	// Un XFF distinto en cada solicitud no da una cuenta nueva.
+	for i := range 5 {
+		if status := solicitud(fmt.Sprintf("198.51.100.%d", i)); status != http.StatusTooManyRequests {
+			t.Fatalf("XFF %d: status %d", i, status)
+		}
+	}
+	if n := len(limitador.buckets); n != 1 {
+		t.Errorf("buckets: %d, se esperaba 1", n)
+	}
+
+	// Con IPExtractor sí se toma en cuenta el header del proxy.
+	_, proxy, _ := net.ParseCIDR("203.0.113.0/24")
+	g.IPExtractor = ExtractIPFromXFFHeader(TrustIPRange(proxy))
+	if status := solicitud("198.51.100.1"); status != http.StatusOK {
+		t.Errorf("XFF de proxy confiable: status %d", status)
+	}
+}
+
+func TestLimitadorMaxClientes(t *testing.T) {
+	limitador := NuevoLimitadorSolicitudes(1, time.Minute)
+	limitador.MaxClientes = 3
+	g := New()
+	g.GET("/", func(c *Context) error { return c.StringOk("ok") }, limitador.Middleware)
+	for i := range 10 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = fmt.Sprintf("203.0.113.%d:40000", i)
+		g.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if n := len(limitador.buckets); n > 3 {
+		t.Errorf("buckets: %d, máximo 3", n)
+	}
+}
@@ -0,0 +1,422 @@
+package gecko
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkoid"
+	"github.com/pargomx/gecko/gkt"
+)
+
+// ================================================================ //
+// ========== BIND ================================================ //
+
+// Llena los campos de la struct apuntada por dst con los valores de la
+// solicitud según sus tags, y los valida. Por ejemplo:
+//
+//	type Args struct {
+//		ID       gkoid.Decimal `path:"id"`
+//		Pagina   int           `query:"page" validate:"min=1"`
+//		Nombre   string        `form:"nombre" label:"Nombre" validate:"required,max=60"`
+//		CP       string        `form:"cp" label:"Código postal" regex:"^[0-9]{5}$"`
+//		Precio   int           `form:"precio,centavos" validate:"min=0"`
+//		Fecha    *time.Time    `form:"fecha"`
+//		Etiqueta []string      `form:"etiqueta" validate:"max=5"`
+//	}
+//	var args Args
+//	if err := c.Bind(&args); err != nil {
+//		return err
+//	}
+//
+// Fuentes: `path`, `query` y `form` (que incluye el query como c.FormValue).
+//
+// Opciones después de la clave:
+//   - talcual: no quitar espacios extra al string (como FormTalCual).
+//   - centavos: convertir "$1,200.50" a 120050 con gkt.ToCentavos.
+//   - fechahora: usar gkt.ToFechaHora en lugar de gkt.ToFecha.
+//
+// Reglas en `validate`: required, min=N, max=N, len=N. Para números se
+// compara el valor, para strings el número de caracteres y para slices
+// el número de elementos. El tag `regex` valida cada valor recibido.
+//
+// Los campos sin valor en la solicitud no se modifican. Los structs
+// embebidos se recorren también.
+//
+// Si hay campos inválidos retorna un solo gko.ErrDatoInvalido con un
+// mensaje para el usuario que enlista todos y el mensaje de cada campo
+// disponible con err.GetCampos() para volver a mostrar el formulario.
+func (c *Context) Bind(dst any) error {
+	op := gko.Op("gecko.Bind")
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return op.E(gko.ErrInesperado).Strf("dst debe ser pointer a struct, no %T", dst)
+	}
+	b := &binder{c: c}
+	err := b.bindStruct(val.Elem())
+	if err != nil {
+		return op.Err(err)
+	}
+	if len(b.inválidos) > 0 {
+		op.E(gko.ErrDatoInvalido)
+		msgs := make([]string, len(b.inválidos))
+		for i, inv := range b.inválidos {
+			msgs[i] = inv.mensaje
+			op.Campo(inv.clave, inv.mensaje)
+		}
+		return op.Msg(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// ================================================================ //
+
+type binder struct {
+	c          *Context
+	formParsed bool
+	inválidos  []campoInválido
+}
+
+type campoInválido struct {
+	clave   string
+	mensaje string // Para el usuario.
+}
+
+func (b *binder) invalido(clave, label, msg string) {
+	b.inválidos = append(b.inválidos, campoInválido{
+		clave:   clave,
+		mensaje: label + " " + msg,
+	})
+}
+
+// Recorre los campos exportados de la struct.
+func (b *binder) bindStruct(v reflect.Value) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		campo := t.Field(i)
+		if !campo.IsExported() {
+			continue
+		}
+		fuente, tag := "", ""
+		for _, f := range []string{"path", "query", "form"} {
+			if tag = campo.Tag.Get(f); tag != "" {
+				fuente = f
+				break
+			}
+		}
+		if fuente == "" {
+			if campo.Anonymous && campo.Type.Kind() == reflect.Struct {
+				err := b.bindStruct(v.Field(i))
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		partes := strings.Split(tag, ",")
+		clave, opciones := partes[0], partes[1:]
+		label := campo.Tag.Get("label")
+		if label == "" {
+			label = clave
+		}
+		valores, err := b.valores(fuente, clave)
+		if err != nil {
+			return err
+		}
+		err = b.bindCampo(v.Field(i), campo, clave, label, valores, opciones)
+		if err != nil {
+			return gko.Err(err).Ctx("campo", campo.Name)
+		}
+	}
+	return nil
+}
+
+// Valores recibidos para la clave en la fuente dada.
+func (b *binder) valores(fuente, clave string) ([]string, error) {
+	switch fuente {
+	case "path":
+		if v := b.c.Param(clave); v != "" {
+			return []string{v}, nil
+		}
+		return nil, nil
+	case "query":
+		return b.c.QueryParams()[clave], nil
+	default:
+		if !b.formParsed {
+			_, err := b.c.FormParams()
+			if err != nil {
+				return nil, gko.ErrDatoInvalido.Err(err).Msg("No se pudo leer el formulario")
+			}
+			b.formParsed = true
+		}
+		return b.c.request.Form[clave], nil
+	}
+}
+
+// Asigna los valores al campo y aplica las reglas de validación.
+// Solo retorna error si el campo está mal declarado.
+func (b *binder) bindCampo(v reflect.Value, campo reflect.StructField, clave, label string, valores []string, opciones []string) error {
+	valores = slices.Clone(valores) // No modificar request.Form.
+	talcual := slices.Contains(opciones, "talcual")
+	for i := range valores {
+		if talcual {
+			continue
+		}
+		valores[i] = gkt.SinEspaciosExtra(valores[i])
+	}
+	vacío := true
+	for _, val := range valores {
+		if val != "" {
+			vacío = false
+			break
+		}
+	}
+	reglas := parseReglas(campo.Tag.Get("validate"))
+	if vacío {
+		if _, ok := reglas["required"]; ok {
+			b.invalido(clave, label, "es obligatorio")
+		}
+		return nil
+	}
+
+	// Asignar valor.
+	if v.Kind() == reflect.Slice && v.Type() != tipoBytes {
+		slice := reflect.MakeSlice(v.Type(), 0, len(valores))
+		for _, val := range valores {
+			if val == "" {
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			msg, err := asignar(elem, val, opciones)
+			if err != nil {
+				return err
+			}
+			if msg != "" {
+				b.invalido(clave, label, msg)
+				return nil
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		v.Set(slice)
+	} else {
+		msg, err := asignar(v, valores[0], opciones)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			b.invalido(clave, label, msg)
+			return nil
+		}
+	}
+
+	// Validar formato.
+	if expr := campo.Tag.Get("regex"); expr != "" {
+		re, err := getRegex(expr)
+		if err != nil {
+			return gko.ErrInesperado.Err(err).Str("regex inválida")
+		}
+		for _, val := range valores {
+			if val != "" && !re.MatchString(val) {
+				b.invalido(clave, label, "no tiene el formato correcto")
+				return nil
+			}
+		}
+	}
+
+	// Validar reglas de longitud o rango.
+	for _, regla := range []string{"len", "min", "max"} {
+		param, ok := reglas[regla]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return gko.ErrInesperado.Strf("regla %v=%v inválida", regla, param)
+		}
+		msg := validarRegla(v, regla, n)
+		if msg != "" {
+			b.invalido(clave, label, msg)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ================================================================ //
+// ========== CONVERSIÓN ========================================== //
+
+var (
+	tipoBytes   = reflect.TypeOf([]byte(nil))
+	tipoTime    = reflect.TypeOf(time.Time{})
+	tipoDecimal = reflect.TypeOf(gkoid.Decimal(0))
+	tipoHex     = reflect.TypeOf(gkoid.Hex(0))
+	tipoAlfanum = reflect.TypeOf(gkoid.Alfanum(0))
+)
+
+// Convierte el texto al tipo de v y lo asigna. Si el texto es inválido
+// retorna un mensaje para el usuario. Si el tipo no se soporta retorna error.
+func asignar(v reflect.Value, txt string, opciones []string) (string, error) {
+	switch v.Type() {
+	case tipoTime:
+		var t time.Time
+		var err error
+		if slices.Contains(opciones, "fechahora") {
+			t, err = gkt.ToFechaHora(txt)
+		} else {
+			t, err = gkt.ToFecha(txt)
+		}
+		if err != nil {
+			return "no es una fecha válida", nil
+		}
+		v.Set(reflect.ValueOf(t))
+		return "", nil
+	case tipoDecimal:
+		id, err := gkoid.ParseDecimal(gkt.SinEspaciosNinguno(txt))
+		if err != nil {
+			return "no es un identificador válido", nil
+		}
+		v.Set(reflect.ValueOf(id))
+		return "", nil
+	case tipoHex:
+		id, err := gkoid.ParseHex(gkt.SinEspaciosNinguno(txt))
+		if err != nil {
+			return "no es un identificador válido", nil
+		}
+		v.Set(reflect.ValueOf(id))
+		return "", nil
+	case tipoAlfanum:
+		id, err := gkoid.ParseAlfanum(gkt.SinEspaciosNinguno(txt))
+		if err != nil {
+			return "no es un identificador válido", nil
+		}
+		v.Set(reflect.ValueOf(id))
+		return "", nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		msg, err := asignar(elem.Elem(), txt, opciones)
+		if msg == "" && err == nil {
+			v.Set(elem)
+		}
+		return msg, err
+
+	case reflect.String:
+		v.SetString(txt)
+
+	case reflect.Bool:
+		v.SetBool(gkt.ToBool(txt))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if slices.Contains(opciones, "centavos") {
+			num, err := gkt.ToCentavos(txt)
+			if err != nil {
+				return "no es una cantidad de dinero válida", nil
+			}
+			v.SetInt(int64(num))
+			return "", nil
+		}
+		num, err := strconv.ParseInt(gkt.SinEspaciosNinguno(txt), 10, v.Type().Bits())
+		if err != nil {
+			return "debe ser un número entero", nil
+		}
+		v.SetInt(num)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := strconv.ParseUint(gkt.SinEspaciosNinguno(txt), 10, v.Type().Bits())
+		if err != nil {
+			return "debe ser un número entero positivo", nil
+		}
+		v.SetUint(num)
+
+	case reflect.Float32, reflect.Float64:
+		num, err := strconv.ParseFloat(gkt.SinEspaciosNinguno(txt), v.Type().Bits())
+		if err != nil {
+			return "debe ser un número", nil
+		}
+		v.SetFloat(num)
+
+	default:
+		return "", gko.ErrInesperado.Strf("tipo %v no soportado", v.Type())
+	}
+	return "", nil
+}
+
+// ================================================================ //
+// ========== VALIDACIÓN ========================================== //
+
+// Reglas "required,min=3,max=10" como mapa regla=parámetro.
+func parseReglas(tag string) map[string]string {
+	reglas := map[string]string{}
+	for _, regla := range strings.Split(tag, ",") {
+		regla = strings.TrimSpace(regla)
+		if regla == "" {
+			continue
+		}
+		nombre, param, _ := strings.Cut(regla, "=")
+		reglas[nombre] = param
+	}
+	return reglas
+}
+
+// Aplica la regla len, min o max y retorna el mensaje si no se cumple.
+func validarRegla(v reflect.Value, regla string, n float64) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	var medida float64
+	var unidad string
+	switch v.Kind() {
+	case reflect.String:
+		medida, unidad = float64(utf8.RuneCountInString(v.String())), " caracteres"
+	case reflect.Slice:
+		medida, unidad = float64(v.Len()), " elementos"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		medida = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		medida = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		medida = v.Float()
+	default:
+		return ""
+	}
+	num := strconv.FormatFloat(n, 'f', -1, 64)
+	switch {
+	case regla == "len" && medida != n:
+		return "debe tener exactamente " + num + unidad
+	case regla == "min" && medida < n && unidad != "":
+		return "debe tener mínimo " + num + unidad
+	case regla == "min" && medida < n:
+		return "debe ser mínimo " + num
+	case regla == "max" && medida > n && unidad != "":
+		return "debe tener máximo " + num + unidad
+	case regla == "max" && medida > n:
+		return "debe ser máximo " + num
+	}
+	return ""
+}
+
+// Las expresiones regulares de los tags se compilan una sola vez.
+var regexCache sync.Map
+
+func getRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(expr, re)
+	return re, nil
+}
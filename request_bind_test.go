@@ -0,0 +1,236 @@
+package gecko
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkoid"
+)
+
+// Contexto para un POST con el form y los path values dados en pares.
+func contextBind(target string, form url.Values, pathValues ...string) *Context {
+	req := postForm(target, form)
+	for i := 0; i+1 < len(pathValues); i += 2 {
+		req.SetPathValue(pathValues[i], pathValues[i+1])
+	}
+	return New().nuevoContext(httptest.NewRecorder(), req, "POST /")
+}
+
+type BindPaginacion struct {
+	Pagina int `query:"page"`
+}
+
+func TestBindFuentesYOpciones(t *testing.T) {
+	var args struct {
+		BindPaginacion
+		ID        gkoid.Decimal `path:"id"`
+		Orden     string        `form:"orden"` // El form incluye el query.
+		Nombre    string        `form:"nombre"`
+		Nota      string        `form:"nota,talcual"`
+		Precio    int           `form:"precio,centavos"`
+		Fecha     time.Time     `form:"fecha"`
+		Hora      *time.Time    `form:"hora,fechahora"`
+		Ausente   *time.Time    `form:"ausente"`
+		Activo    bool          `form:"activo"`
+		Etiquetas []string      `form:"etiqueta"`
+		Cantidad  uint8         `form:"cantidad"`
+		Peso      float64       `form:"peso"`
+		Intacto   string        `form:"intacto"`
+		sinBind   string        `form:"nombre"`
+	}
+	args.Intacto = "previo"
+	c := contextBind("/items/15?page=3&orden=desc", url.Values{
+		"nombre":   {"  Ana   María "},
+		"nota":     {"  uno\n  dos "},
+		"precio":   {"$1,200.50"},
+		"fecha":    {"2024-03-15"},
+		"hora":     {"2024-03-15 10:30:00"},
+		"activo":   {"on"},
+		"etiqueta": {"a", "", "b"},
+		"cantidad": {"255"},
+		"peso":     {"1.5"},
+	}, "id", "15")
+	if err := c.Bind(&args); err != nil {
+		t.Fatal(err)
+	}
+	casos := []struct {
+		campo         string
+		obtenido, esp any
+	}{
+		{"page", args.Pagina, 3},
+		{"id", args.ID, gkoid.Decimal(15)},
+		{"orden", args.Orden, "desc"},
+		{"nombre", args.Nombre, "Ana María"},
+		{"nota", args.Nota, "  uno\n  dos "},
+		{"precio", args.Precio, 120050},
+		{"fecha", args.Fecha.Format(time.DateOnly), "2024-03-15"},
+		{"hora", args.Hora.Format(time.DateTime), "2024-03-15 10:30:00"},
+		{"ausente", args.Ausente == nil, true},
+		{"activo", args.Activo, true},
+		{"etiqueta", strings.Join(args.Etiquetas, "|"), "a|b"},
+		{"cantidad", args.Cantidad, uint8(255)},
+		{"peso", args.Peso, 1.5},
+		{"intacto", args.Intacto, "previo"},
+		{"sinBind", args.sinBind, ""},
+	}
+	for _, caso := range casos {
+		if caso.obtenido != caso.esp {
+			t.Errorf("%s: %v, se esperaba %v", caso.campo, caso.obtenido, caso.esp)
+		}
+	}
+}
+
+func TestBindValidacion(t *testing.T) {
+	casos := []struct {
+		nombre  string
+		dst     any
+		valor   []string
+		mensaje string // Vacío si es válido.
+	}{
+		{"required", &struct {
+			X string `form:"x" validate:"required"`
+		}{}, []string{"  "}, "x es obligatorio"},
+		{"required con label", &struct {
+			X string `form:"x" label:"Nombre" validate:"required"`
+		}{}, nil, "Nombre es obligatorio"},
+		{"required slice", &struct {
+			X []int `form:"x" validate:"required"`
+		}{}, []string{"", ""}, "x es obligatorio"},
+		{"min caracteres", &struct {
+			X string `form:"x" validate:"min=3"`
+		}{}, []string{"añ"}, "x debe tener mínimo 3 caracteres"},
+		{"min caracteres válido", &struct {
+			X string `form:"x" validate:"min=3"`
+		}{}, []string{"año"}, ""},
+		{"max número", &struct {
+			X int `form:"x" validate:"max=10"`
+		}{}, []string{"11"}, "x debe ser máximo 10"},
+		{"min número", &struct {
+			X float64 `form:"x" validate:"min=0.5"`
+		}{}, []string{"0.25"}, "x debe ser mínimo 0.5"},
+		{"min puntero", &struct {
+			X *int `form:"x" validate:"min=1"`
+		}{}, []string{"0"}, "x debe ser mínimo 1"},
+		{"len", &struct {
+			X string `form:"x" validate:"len=5"`
+		}{}, []string{"1234"}, "x debe tener exactamente 5 caracteres"},
+		{"max elementos", &struct {
+			X []string `form:"x" validate:"max=2"`
+		}{}, []string{"a", "b", "c"}, "x debe tener máximo 2 elementos"},
+		{"regex", &struct {
+			X string `form:"x" regex:"^[0-9]{5}$"`
+		}{}, []string{"12a45"}, "x no tiene el formato correcto"},
+		{"regex en cada valor", &struct {
+			X []string `form:"x" regex:"^[a-z]+$"`
+		}{}, []string{"abc", "ABC"}, "x no tiene el formato correcto"},
+		{"regex válida", &struct {
+			X string `form:"x" regex:"^[0-9]{5}$"`
+		}{}, []string{"12345"}, ""},
+		{"entero desbordado", &struct {
+			X int8 `form:"x"`
+		}{}, []string{"300"}, "x debe ser un número entero"},
+		{"entero negativo", &struct {
+			X uint `form:"x"`
+		}{}, []string{"-1"}, "x debe ser un número entero positivo"},
+		{"número inválido", &struct {
+			X float32 `form:"x"`
+		}{}, []string{"uno"}, "x debe ser un número"},
+		{"centavos inválidos", &struct {
+			X int `form:"x,centavos"`
+		}{}, []string{"1.234"}, "x no es una cantidad de dinero válida"},
+		{"fecha inválida", &struct {
+			X time.Time `form:"x"`
+		}{}, []string{"2024-13-45"}, "x no es una fecha válida"},
+		{"fechahora sin hora", &struct {
+			X time.Time `form:"x,fechahora"`
+		}{}, []string{"2024-03-15"}, "x no es una fecha válida"},
+		{"identificador inválido", &struct {
+			X gkoid.Hex `form:"x"`
+		}{}, []string{"xyz"}, "x no es un identificador válido"},
+	}
+	for _, caso := range casos {
+		err := contextBind("/", url.Values{"x": caso.valor}).Bind(caso.dst)
+		if caso.mensaje == "" {
+			if err != nil {
+				t.Errorf("%s: %v", caso.nombre, err)
+			}
+			continue
+		}
+		if !gko.Is(err, gko.ErrDatoInvalido) {
+			t.Errorf("%s: se esperaba ErrDatoInvalido, no %v", caso.nombre, err)
+			continue
+		}
+		if campos := gko.Err(err).GetCampos(); campos["x"] != caso.mensaje {
+			t.Errorf("%s: campos %v, se esperaba %q", caso.nombre, campos, caso.mensaje)
+		}
+	}
+}
+
+func TestBindVariosInvalidos(t *testing.T) {
+	var args struct {
+		Nombre string `form:"nombre" label:"Nombre" validate:"required"`
+		Edad   int    `form:"edad" label:"Edad" validate:"min=18"`
+		Correo string `form:"correo" label:"Correo"`
+	}
+	err := contextBind("/", url.Values{"edad": {"12"}, "correo": {"a@b.c"}}).Bind(&args)
+	gkerr := gko.Err(err)
+	if gkerr.GetMensaje() != "Nombre es obligatorio; Edad debe ser mínimo 18." {
+		t.Errorf("mensaje: %q", gkerr.GetMensaje())
+	}
+	if campos := gkerr.GetCampos(); len(campos) != 2 || campos["nombre"] == "" || campos["edad"] == "" {
+		t.Errorf("campos: %v", campos)
+	}
+	// Los campos válidos sí se asignan.
+	if args.Correo != "a@b.c" || args.Edad != 12 {
+		t.Errorf("campos asignados: %+v", args)
+	}
+}
+
+// Un destino o campo mal declarado es error del desarrollador y
+// se reporta como gko.ErrInesperado sin panic.
+func TestBindMalDeclarado(t *testing.T) {
+	var número int
+	casos := []struct {
+		nombre string
+		dst    any
+	}{
+		{"nil", nil},
+		{"struct sin pointer", struct{}{}},
+		{"pointer nil", (*BindPaginacion)(nil)},
+		{"pointer a int", &número},
+		{"tipo no soportado", &struct {
+			X map[string]string `form:"x"`
+		}{}},
+		{"slice de tipo no soportado", &struct {
+			X []chan int `form:"x"`
+		}{}},
+		{"regex inválida", &struct {
+			X string `form:"x" regex:"[0-9"`
+		}{}},
+		{"regla inválida", &struct {
+			X string `form:"x" validate:"max=diez"`
+		}{}},
+	}
+	for _, caso := range casos {
+		err := contextBind("/", url.Values{"x": {"1"}}).Bind(caso.dst)
+		if !gko.Is(err, gko.ErrInesperado) {
+			t.Errorf("%s: se esperaba ErrInesperado, no %v", caso.nombre, err)
+		}
+	}
+
+	// Los campos no exportados se ignoran aunque tengan tag.
+	var args struct {
+		x          int `form:"x"`
+		paginación BindPaginacion
+	}
+	if err := contextBind("/?page=2", url.Values{"x": {"1"}}).Bind(&args); err != nil {
+		t.Errorf("campos no exportados: %v", err)
+	}
+	if args.x != 0 || args.paginación.Pagina != 0 {
+		t.Errorf("se asignaron campos no exportados: %+v", args)
+	}
+}
@@ -0,0 +1,101 @@
+package gecko
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkt"
+)
+
+// ================================================================ //
+// ========== LÍMITES DEL BODY ==================================== //
+
+// El body no se limita a menos que se active para todas las rutas con
+// g.LimiteBody o para algunas con LimitarBody en la ruta o en el grupo:
+//
+//	g.LimiteBody = 1 << 20 // 1 MB
+//	g.POST("/fotos", s.postFoto).LimitarBody(50 << 20)
+
+// Límite del body para esta ruta en lugar de g.LimiteBody.
+// Cero o negativo para no limitarlo.
+func (rt *Ruta) LimitarBody(bytes int64) *Ruta {
+	rt.limiteBody = &bytes
+	return rt
+}
+
+// Límite para cada archivo de un multipart en esta ruta en lugar
+// de g.LimiteArchivo. Cero o negativo para no limitarlo.
+func (rt *Ruta) LimitarArchivo(bytes int64) *Ruta {
+	rt.limiteArchivo = &bytes
+	return rt
+}
+
+// Límite del body para las rutas del grupo registradas después.
+func (gr *Grupo) LimitarBody(bytes int64) {
+	gr.limiteBody = &bytes
+}
+
+// Límite para cada archivo de un multipart en las rutas del grupo registradas después.
+func (gr *Grupo) LimitarArchivo(bytes int64) {
+	gr.limiteArchivo = &bytes
+}
+
+// Aplica los límites de la ruta a la solicitud antes del handler.
+// Si el Content-Length declarado ya excede el límite se rechaza sin
+// leer el body. Si no, se envuelve con http.MaxBytesReader para que
+// la lectura falle al excederlo aunque no se haya declarado.
+func (c *Context) limitarBody(rt *Ruta) error {
+	c.limiteArchivo = c.gecko.LimiteArchivo
+	if rt.limiteArchivo != nil {
+		c.limiteArchivo = *rt.limiteArchivo
+	}
+	limite := c.gecko.LimiteBody
+	if rt.limiteBody != nil {
+		limite = *rt.limiteBody
+	}
+	if limite <= 0 || c.request.Body == nil || c.request.Body == http.NoBody {
+		return nil
+	}
+	if c.request.ContentLength > limite {
+		return errTooBig(limite)
+	}
+	c.request.Body = http.MaxBytesReader(c.response.Writer, c.request.Body, limite)
+	return nil
+}
+
+// Convierte el error de http.MaxBytesReader en gko.ErrTooBig.
+// Cualquier otro error se devuelve igual.
+func errorBody(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return errTooBig(maxErr.Limit)
+	}
+	return err
+}
+
+func errTooBig(limite int64) error {
+	return gko.ErrTooBig.Msgf("El contenido excede el límite de %s", gkt.ByteCountSI(limite)).
+		Strf("body mayor a %d bytes", limite)
+}
+
+// Rechaza el formulario si alguno de sus archivos excede c.limiteArchivo.
+//
+// Se revisa después de que net/http leyó el multipart completo, por lo que
+// no evita leer un archivo grande; lo que sí se lee lo acota el límite del
+// body. Para cortar la lectura en cuanto un archivo excede el límite usar
+// c.SaveUpload, que lo revisa en streaming.
+func (c *Context) validarArchivos() error {
+	if c.limiteArchivo <= 0 || c.request.MultipartForm == nil {
+		return nil
+	}
+	for campo, archivos := range c.request.MultipartForm.File {
+		for _, fh := range archivos {
+			if fh.Size > c.limiteArchivo {
+				return gko.ErrTooBig.Msgf("El archivo %s excede el límite de %s", fh.Filename, gkt.ByteCountSI(c.limiteArchivo)).
+					Strf("archivo de %d bytes en campo '%s'", fh.Size, campo)
+			}
+		}
+	}
+	return nil
+}
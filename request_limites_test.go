@@ -0,0 +1,115 @@
+package gecko
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Multipart con un archivo del tamaño dado en el campo "archivo".
+func multipartArchivo(ruta string, tamaño int) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("nombre", "a")
+	fw, _ := mw.CreateFormFile("archivo", "datos.txt")
+	fw.Write(bytes.Repeat([]byte("x"), tamaño))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, ruta, &body)
+	req.Header.Set(HeaderContentType, mw.FormDataContentType())
+	return req
+}
+
+func TestLimiteBody(t *testing.T) {
+	var ejecutado bool
+	leerForm := func(c *Context) error {
+		ejecutado = true
+		form, err := c.FormParams()
+		if err != nil {
+			return err
+		}
+		return c.StringOk(form.Get("relleno")[:3])
+	}
+	g := New()
+	g.POST("/sin-limite", leerForm)
+	g.POST("/ruta", leerForm).LimitarBody(100)
+	grupo := g.Group("/grupo")
+	grupo.LimitarBody(100)
+	grupo.POST("/form", leerForm)
+	grupo.POST("/libre", leerForm).LimitarBody(0)
+
+	grande := url.Values{"relleno": {strings.Repeat("x", 200)}}
+	chico := url.Values{"relleno": {"xyz"}}
+	casos := []struct {
+		nombre    string
+		ruta      string
+		form      url.Values
+		declarado bool // Con Content-Length.
+		status    int
+		ejecutado bool
+	}{
+		{"sin límite por default", "/sin-limite", url.Values{"relleno": {strings.Repeat("x", 1<<20)}}, true, http.StatusOK, true},
+		{"dentro del límite", "/ruta", chico, true, http.StatusOK, true},
+		{"Content-Length excedido", "/ruta", grande, true, http.StatusRequestEntityTooLarge, false},
+		{"sin Content-Length", "/ruta", grande, false, http.StatusRequestEntityTooLarge, true},
+		{"límite del grupo", "/grupo/form", grande, true, http.StatusRequestEntityTooLarge, false},
+		{"ruta sin límite en grupo", "/grupo/libre", grande, false, http.StatusOK, true},
+	}
+	for _, caso := range casos {
+		ejecutado = false
+		req := postForm(caso.ruta, caso.form)
+		if !caso.declarado {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status || ejecutado != caso.ejecutado {
+			t.Errorf("%s: status %d, handler ejecutado %v", caso.nombre, rec.Code, ejecutado)
+		}
+	}
+
+	// El límite global aplica a las rutas sin el suyo.
+	g.LimiteBody = 100
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, postForm("/sin-limite", grande))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("g.LimiteBody: status %d", rec.Code)
+	}
+}
+
+func TestLimiteArchivo(t *testing.T) {
+	leerArchivo := func(c *Context) error {
+		fh, err := c.FormFile("archivo")
+		if err != nil {
+			return err
+		}
+		return c.StringOk(fh.Filename)
+	}
+	g := New()
+	g.LimiteArchivo = 100
+	g.POST("/global", leerArchivo)
+	g.POST("/ruta", leerArchivo).LimitarArchivo(10)
+	g.POST("/libre", leerArchivo).LimitarArchivo(0)
+
+	casos := []struct {
+		ruta   string
+		tamaño int
+		status int
+	}{
+		{"/global", 100, http.StatusOK},
+		{"/global", 101, http.StatusRequestEntityTooLarge},
+		{"/ruta", 10, http.StatusOK},
+		{"/ruta", 11, http.StatusRequestEntityTooLarge},
+		{"/libre", 1000, http.StatusOK},
+	}
+	for _, caso := range casos {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, multipartArchivo(caso.ruta, caso.tamaño))
+		if rec.Code != caso.status {
+			t.Errorf("%s con %d bytes: status %d", caso.ruta, caso.tamaño, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package gecko
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkt"
+)
+
+// ================================================================ //
+// ========== GUARDAR ARCHIVOS SUBIDOS ============================ //
+
+// Opciones para c.SaveUpload.
+type OpcionesUpload struct {
+	// MIME permitidos según el contenido real del archivo, no según
+	// la extensión o lo que diga el cliente. Acepta comodines como
+	// "image/*". Vacío para permitir cualquiera.
+	MIMEs []string
+
+	// Máximo de bytes del archivo. Cero para usar el límite de archivo
+	// de la ruta o g.LimiteArchivo.
+	MaxBytes int64
+}
+
+// Metadatos del archivo guardado por c.SaveUpload.
+type ArchivoSubido struct {
+	Ruta           string // Donde quedó guardado. Ej: "uploads/3f/3f2a...c1.png".
+	Nombre         string // Relativo al directorio dado. Ej: "3f/3f2a...c1.png".
+	NombreOriginal string // El que envió el cliente. No confiar en él.
+	MIME           string // Detectado a partir del contenido.
+	Tamaño         int64  // En bytes.
+	SHA256         string // Hexadecimal.
+	YaExistía      bool   // Ya había un archivo con el mismo contenido.
+}
+
+// Guarda en dir el archivo recibido en el campo dado de un formulario
+// multipart. Se escribe a un archivo temporal mientras se lee el body,
+// se verifica su tipo real y tamaño, y se mueve a una ruta según su hash
+// "dir/3f/3f2a...c1.png" para que el mismo contenido se guarde una vez.
+//
+//	archivo, err := c.SaveUpload("foto", "uploads", gecko.OpcionesUpload{
+//		MIMEs: []string{"image/png", "image/jpeg"},
+//	})
+//
+// Retorna gko.ErrNoSoportado si el tipo no está permitido y gko.ErrTooBig
+// si excede el límite. Los demás campos de texto del formulario quedan
+// disponibles con c.FormValue. Para recibir varios archivos en la misma
+// solicitud usar c.MultipartForm.
+//
+// Si el formulario no se había leído, SaveUpload lo consume en streaming
+// y solo se puede llamar una vez: otra llamada, c.FormFile o c.MultipartForm
+// retornan gko.ErrNoDisponible.
+func (c *Context) SaveUpload(campo string, dir string, opts OpcionesUpload) (*ArchivoSubido, error) {
+	op := gko.Op("gecko.SaveUpload").Ctx("campo", campo)
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = c.limiteArchivo
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, op.E(gko.ErrAlEscribir).Err(err)
+	}
+
+	// Si el formulario ya se leyó se toma de ahí, si no se lee en streaming.
+	if c.multipartConsumido {
+		return nil, op.Err(errMultipartConsumido())
+	}
+	var (
+		origen io.Reader
+		nombre string
+		mr     *multipart.Reader
+	)
+	if c.multipart == nil && c.request.MultipartForm != nil {
+		fh, err := c.FormFile(campo)
+		if err != nil {
+			return nil, op.E(gko.ErrDatoIndef).Err(err).Msgf("Falta el archivo %s", campo)
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, op.E(gko.ErrAlLeer).Err(err)
+		}
+		defer f.Close()
+		origen, nombre = f, fh.Filename
+	} else {
+		var err error
+		mr, err = c.lectorMultipart()
+		if err != nil {
+			return nil, op.E(gko.ErrDatoInvalido).Err(err).Msg("Se esperaba un formulario multipart")
+		}
+		defer func() { // Queda leído hasta donde llegó.
+			c.multipart = nil
+			c.multipartConsumido = true
+		}()
+		part, err := c.buscarParteArchivo(mr, campo)
+		if err != nil {
+			return nil, op.Err(err)
+		}
+		defer part.Close()
+		origen, nombre = part, part.FileName()
+	}
+
+	archivo, err := guardarUpload(origen, dir, opts)
+	if err != nil {
+		return nil, op.Err(err).Ctx("archivo", nombre)
+	}
+	archivo.NombreOriginal = filepath.Base(nombre)
+
+	// Leer el resto para tener los demás campos del formulario.
+	if mr != nil {
+		if err := c.leerPartesRestantes(mr); err != nil {
+			return archivo, op.Err(err)
+		}
+	}
+	return archivo, nil
+}
+
+// Avanza por el multipart hasta la parte con el archivo del campo dado
+// guardando en el Form los valores de texto que encuentre antes.
+func (c *Context) buscarParteArchivo(mr *multipart.Reader, campo string) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, gko.ErrDatoIndef.Msgf("Falta el archivo %s", campo)
+		}
+		if err != nil {
+			return nil, errorBody(err)
+		}
+		if part.FormName() == campo && part.FileName() != "" {
+			return part, nil
+		}
+		if err := c.agregarValorForm(part); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Lee las partes después del archivo guardando sus valores de texto.
+// Otros archivos se descartan.
+func (c *Context) leerPartesRestantes(mr *multipart.Reader) error {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errorBody(err)
+		}
+		if err := c.agregarValorForm(part); err != nil {
+			return err
+		}
+	}
+}
+
+// Agrega el valor de una parte de texto al Form de la solicitud.
+func (c *Context) agregarValorForm(part *multipart.Part) error {
+	defer part.Close()
+	if part.FormName() == "" || part.FileName() != "" {
+		return nil
+	}
+	valor, err := io.ReadAll(io.LimitReader(part, defaultMemory))
+	if err != nil {
+		return errorBody(err)
+	}
+	c.request.Form.Add(part.FormName(), string(valor))
+	c.request.PostForm.Add(part.FormName(), string(valor))
+	return nil
+}
+
+// Escribe el contenido a un temporal en dir verificando su tipo y tamaño
+// y calculando su hash. Luego lo mueve a su ruta según el hash.
+func guardarUpload(origen io.Reader, dir string, opts OpcionesUpload) (*ArchivoSubido, error) {
+	// Detectar el tipo con los primeros bytes antes de escribir nada.
+	inicio := make([]byte, 512)
+	n, err := io.ReadFull(origen, inicio)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errorBody(err)
+	}
+	inicio = inicio[:n]
+	if n == 0 {
+		return nil, gko.ErrDatoIndef.Msg("El archivo está vacío")
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(inicio))
+	if !mimePermitido(mimeType, opts.MIMEs) {
+		return nil, gko.ErrNoSoportado.Msgf("Tipo de archivo no permitido: %s", mimeType)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".subiendo-*")
+	if err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	defer os.Remove(tmp.Name()) // Ya no existe si se renombró.
+	defer tmp.Close()
+
+	hash := sha256.New()
+	var contenido io.Reader = io.MultiReader(bytes.NewReader(inicio), origen)
+	if opts.MaxBytes > 0 {
+		contenido = io.LimitReader(contenido, opts.MaxBytes+1)
+	}
+	tamaño, err := io.Copy(io.MultiWriter(tmp, hash), contenido)
+	if err != nil {
+		if err = errorBody(err); gko.Is(err, gko.ErrTooBig) {
+			return nil, err
+		}
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	if opts.MaxBytes > 0 && tamaño > opts.MaxBytes {
+		return nil, gko.ErrTooBig.Msgf("El archivo excede el límite de %s", gkt.ByteCountSI(opts.MaxBytes))
+	}
+	if err := tmp.Sync(); err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+
+	archivo := &ArchivoSubido{
+		MIME:   mimeType,
+		Tamaño: tamaño,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}
+	archivo.Nombre = filepath.Join(archivo.SHA256[:2], archivo.SHA256+extensiónMIME(mimeType))
+	archivo.Ruta = filepath.Join(dir, archivo.Nombre)
+
+	if _, err := os.Stat(archivo.Ruta); err == nil {
+		archivo.YaExistía = true
+		return archivo, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, gko.ErrAlLeer.Err(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivo.Ruta), 0755); err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	if err := os.Rename(tmp.Name(), archivo.Ruta); err != nil {
+		return nil, gko.ErrAlEscribir.Err(err)
+	}
+	return archivo, nil
+}
+
+// Reporta si el MIME está en la lista, que puede tener comodines como "image/*".
+func mimePermitido(mimeType string, permitidos []string) bool {
+	if len(permitidos) == 0 {
+		return true
+	}
+	for _, p := range permitidos {
+		if p == mimeType || p == "*/*" {
+			return true
+		}
+		if prefijo, ok := strings.CutSuffix(p, "/*"); ok && strings.HasPrefix(mimeType, prefijo+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Extensión para el MIME detectado. No se usa la del nombre original
+// para que no se pueda guardar un ".html" que en realidad es otra cosa.
+func extensiónMIME(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "text/plain":
+		return ".txt"
+	case "application/octet-stream":
+		return ""
+	}
+	exts, err := mime.ExtensionsByType(mimeType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+	return exts[0]
+}
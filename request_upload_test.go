@@ -0,0 +1,112 @@
+package gecko
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+func contextUpload(tamaño int) *Context {
+	return New().nuevoContext(httptest.NewRecorder(), multipartArchivo("/", tamaño), "POST /")
+}
+
+// Archivos en dir sin contar subdirectorios, incluidos los temporales.
+func archivosEn(t *testing.T, dir string) []string {
+	t.Helper()
+	var archivos []string
+	err := filepath.WalkDir(dir, func(ruta string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			archivos = append(archivos, ruta)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archivos
+}
+
+func TestSaveUpload(t *testing.T) {
+	dir := t.TempDir()
+	c := contextUpload(20)
+	archivo, err := c.SaveUpload("archivo", dir, OpcionesUpload{MIMEs: []string{"text/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suma := sha256.Sum256([]byte(strings.Repeat("x", 20)))
+	hash := hex.EncodeToString(suma[:])
+	nombre := filepath.Join(hash[:2], hash+".txt")
+	if archivo.SHA256 != hash || archivo.Nombre != nombre || archivo.Ruta != filepath.Join(dir, nombre) {
+		t.Errorf("ruta por hash: %+v", archivo)
+	}
+	if archivo.MIME != "text/plain" || archivo.Tamaño != 20 || archivo.NombreOriginal != "datos.txt" || archivo.YaExistía {
+		t.Errorf("metadatos: %+v", archivo)
+	}
+	if got := archivosEn(t, dir); len(got) != 1 || got[0] != archivo.Ruta {
+		t.Errorf("archivos en dir: %v", got)
+	}
+	if c.FormValue("nombre") != "a" {
+		t.Errorf("campo de texto antes del archivo: %q", c.FormValue("nombre"))
+	}
+
+	// El body ya se consumió.
+	_, err = c.SaveUpload("archivo", dir, OpcionesUpload{})
+	if !gko.Is(err, gko.ErrNoDisponible) {
+		t.Errorf("segunda llamada: %v", err)
+	}
+	if _, err := c.FormFile("archivo"); !gko.Is(err, gko.ErrNoDisponible) {
+		t.Errorf("FormFile después de SaveUpload: %v", err)
+	}
+
+	// Mismo contenido desde el formulario ya leído.
+	c = contextUpload(20)
+	if _, err := c.MultipartForm(); err != nil {
+		t.Fatal(err)
+	}
+	otro, err := c.SaveUpload("archivo", dir, OpcionesUpload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otro.YaExistía || otro.Ruta != archivo.Ruta {
+		t.Errorf("mismo contenido: %+v", otro)
+	}
+	if got := archivosEn(t, dir); len(got) != 1 {
+		t.Errorf("archivos en dir: %v", got)
+	}
+}
+
+func TestSaveUploadRechazado(t *testing.T) {
+	casos := []struct {
+		nombre string
+		opts   OpcionesUpload
+		limite int64 // De la ruta.
+		err    gko.ErrorKey
+	}{
+		{"MIME no permitido", OpcionesUpload{MIMEs: []string{"image/png", "image/jpeg"}}, 0, gko.ErrNoSoportado},
+		{"excede MaxBytes", OpcionesUpload{MaxBytes: 50}, 0, gko.ErrTooBig},
+		{"excede límite de la ruta", OpcionesUpload{}, 50, gko.ErrTooBig},
+	}
+	for _, caso := range casos {
+		dir := t.TempDir()
+		c := contextUpload(1000)
+		c.limiteArchivo = caso.limite
+		_, err := c.SaveUpload("archivo", dir, caso.opts)
+		if !gko.Is(err, caso.err) {
+			t.Errorf("%s: %v", caso.nombre, err)
+		}
+		if got := archivosEn(t, dir); len(got) != 0 {
+			t.Errorf("%s: quedaron archivos %v", caso.nombre, got)
+		}
+	}
+
+	c := contextUpload(10)
+	if _, err := c.SaveUpload("otro", t.TempDir(), OpcionesUpload{}); !gko.Is(err, gko.ErrDatoIndef) {
+		t.Errorf("campo inexistente: %v", err)
+	}
+}
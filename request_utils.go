@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/pargomx/gecko/gko"
 )
 
 const defaultMemory = 32 << 20 // 32 MB
@@ -32,23 +34,27 @@ func (c *Context) QueryString() string {
 }
 
 func (c *Context) FormValue(name string) string {
+	c.completarMultipart()
 	return c.request.FormValue(name)
 }
 
 func (c *Context) FormParams() (url.Values, error) {
 	if strings.HasPrefix(c.request.Header.Get(HeaderContentType), MIMEMultipartForm) {
-		if err := c.request.ParseMultipartForm(defaultMemory); err != nil {
+		if err := c.parseMultipart(); err != nil {
 			return nil, err
 		}
 	} else {
 		if err := c.request.ParseForm(); err != nil {
-			return nil, err
+			return nil, errorBody(err)
 		}
 	}
 	return c.request.Form, nil
 }
 
 func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if err := c.parseMultipart(); err != nil {
+		return nil, err
+	}
 	f, fh, err := c.request.FormFile(name)
 	if err != nil {
 		return nil, err
@@ -58,10 +64,68 @@ func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
 }
 
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.request.ParseMultipartForm(defaultMemory)
+	err := c.parseMultipart()
 	return c.request.MultipartForm, err
 }
 
+// Lee el multipart respetando los límites del body y de cada archivo.
+// Retorna gko.ErrTooBig si alguno se excede.
+func (c *Context) parseMultipart() error {
+	if c.multipartConsumido {
+		return errMultipartConsumido()
+	}
+	if c.multipart != nil { // Ya se leyó la primera parte en streaming.
+		form, err := c.multipart.ReadForm(defaultMemory)
+		c.multipart = nil
+		if err != nil {
+			return errorBody(err)
+		}
+		for campo, valores := range form.Value {
+			c.request.Form[campo] = append(c.request.Form[campo], valores...)
+			c.request.PostForm[campo] = append(c.request.PostForm[campo], valores...)
+		}
+		form.Value = c.request.PostForm
+		c.request.MultipartForm = form
+		return c.validarArchivos()
+	}
+	if c.request.MultipartForm != nil {
+		return c.validarArchivos()
+	}
+	if err := c.request.ParseMultipartForm(defaultMemory); err != nil {
+		return errorBody(err)
+	}
+	return c.validarArchivos()
+}
+
+// El body ya se leyó hasta el archivo y no se puede volver a leer.
+func errMultipartConsumido() error {
+	return gko.ErrNoDisponible.Msg("El formulario ya se leyó con SaveUpload, para varios archivos usar c.MultipartForm")
+}
+
+// Reader para leer el multipart en streaming. Si ProteccionCSRF ya
+// tomó la primera parte se continúa desde ahí.
+func (c *Context) lectorMultipart() (*multipart.Reader, error) {
+	if c.multipart != nil {
+		return c.multipart, nil
+	}
+	mr, err := c.request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	c.request.ParseForm() // Para tener los valores del query en c.FormValue.
+	c.multipart = mr
+	return mr, nil
+}
+
+// Lee el resto del multipart si se comenzó a leer en streaming para
+// que sus valores estén en el Form. El error se ignora igual que en
+// http.Request.FormValue.
+func (c *Context) completarMultipart() {
+	if c.multipart != nil {
+		c.parseMultipart()
+	}
+}
+
 func (c *Context) IsTLS() bool {
 	return c.request.TLS != nil
 }
@@ -15,17 +15,17 @@ import (
 
 // Valor del form sin modificar.
 func (c *Context) FormTalCual(name string) string {
-	return c.request.FormValue(name)
+	return c.FormValue(name)
 }
 
 // Valor del form espaciado simple sin saltos de línea.
 func (c *Context) FormVal(name string) string {
-	return gkt.SinEspaciosExtra(c.request.FormValue(name))
+	return gkt.SinEspaciosExtra(c.FormValue(name))
 }
 
 // Valor del form espaciado simple sin saltos de línea, sino el default.
 func (c *Context) FormValDefault(name, defaultValue string) string {
-	value := gkt.SinEspaciosExtra(c.request.FormValue(name))
+	value := gkt.SinEspaciosExtra(c.FormValue(name))
 	if value == "" {
 		return defaultValue
 	}
@@ -35,93 +35,93 @@ func (c *Context) FormValDefault(name, defaultValue string) string {
 // Valor del form convertido a bool.
 // Retorna false a menos de que el valor sea: "on", "true", "1".
 func (c *Context) FormBool(name string) bool {
-	return gkt.ToBool(c.request.FormValue(name))
+	return gkt.ToBool(c.FormValue(name))
 }
 
 // Valor del form convertido a entero.
 func (c *Context) FormIntMust(name string) (int, error) {
-	return gkt.ToInt(c.request.FormValue(name))
+	return gkt.ToInt(c.FormValue(name))
 }
 
 // Valor del form convertido a entero sin verificar error (default 0).
 func (c *Context) FormInt(name string) int {
-	num, _ := gkt.ToInt(c.request.FormValue(name))
+	num, _ := gkt.ToInt(c.FormValue(name))
 	return num
 }
 
 // Valor del form convertido a uint64.
 func (c *Context) FormUintMust(name string) (uint, error) {
-	return gkt.ToUint(c.request.FormValue(name))
+	return gkt.ToUint(c.FormValue(name))
 }
 
 // Valor del form convertido a uint64 sin verificar error (default 0).
 func (c *Context) FormUint(name string) uint {
-	num, _ := gkt.ToUint(c.request.FormValue(name))
+	num, _ := gkt.ToUint(c.FormValue(name))
 	return num
 }
 
 // Valor del form convertido a uint64.
 func (c *Context) FormUint64Must(name string) (uint64, error) {
-	return gkt.ToUint64(c.request.FormValue(name))
+	return gkt.ToUint64(c.FormValue(name))
 }
 
 // Valor del form convertido a uint64 sin verificar error (default 0).
 func (c *Context) FormUint64(name string) uint64 {
-	num, _ := gkt.ToUint64(c.request.FormValue(name))
+	num, _ := gkt.ToUint64(c.FormValue(name))
 	return num
 }
 
 // Valor del form convertido a centavos.
 func (c *Context) FormCentavos(name string) (int, error) {
-	return gkt.ToCentavos(c.request.FormValue(name))
+	return gkt.ToCentavos(c.FormValue(name))
 }
 
 // Valor del form convertido a time desde una fecha 28/08/2022 o 2022-02-13.
 func (c *Context) FormFecha(name string) (time.Time, error) {
-	return gkt.ToFecha(c.request.FormValue(name))
+	return gkt.ToFecha(c.FormValue(name))
 }
 
 // Valor del form formato fecha convertido a time, que puede estar indefinido.
 func (c *Context) FormFechaNullable(name string) (*time.Time, error) {
-	return gkt.ToFechaNullable(c.request.FormValue(name))
+	return gkt.ToFechaNullable(c.FormValue(name))
 }
 
 // Valor del form convertido a time desde una fecha con hora.
 func (c *Context) FormFechaHora(name string) (time.Time, error) {
-	return gkt.ToFechaHora(c.request.FormValue(name))
+	return gkt.ToFechaHora(c.FormValue(name))
 }
 
 // Valor del form formato fecha con hora convertido a time, que puede estar indefinido.
 func (c *Context) FormFechaHoraNullable(name string) (*time.Time, error) {
-	return gkt.ToFechaHoraNullable(c.request.FormValue(name))
+	return gkt.ToFechaHoraNullable(c.FormValue(name))
 }
 
 // Valor del form convertido a time.
 func (c *Context) FormTime(name string, layout string) (time.Time, error) {
-	return gkt.ToTime(c.request.FormValue(name), layout)
+	return gkt.ToTime(c.FormValue(name), layout)
 }
 
 // Valor del form convertido a time, que puede estar indefinido.
 func (c *Context) FormTimeNullable(name string, layout string) (*time.Time, error) {
-	return gkt.ToTimeNullable(c.request.FormValue(name), layout)
+	return gkt.ToTimeNullable(c.FormValue(name), layout)
 }
 
 func (c *Context) FormDecimal(name string) gkoid.Decimal {
-	id, err := gkoid.ParseDecimal(c.request.FormValue(name))
+	id, err := gkoid.ParseDecimal(c.FormValue(name))
 	if err != nil {
 		gko.Err(err).Ctx("name", name).Log()
 	}
 	return id
 }
 func (c *Context) FormHex(name string) gkoid.Hex {
-	id, err := gkoid.ParseHex(c.request.FormValue(name))
+	id, err := gkoid.ParseHex(c.FormValue(name))
 	if err != nil {
 		gko.Err(err).Ctx("name", name).Log()
 	}
 	return id
 }
 func (c *Context) FormAlfanum(name string) gkoid.Alfanum {
-	id, err := gkoid.ParseAlfanum(c.request.FormValue(name))
+	id, err := gkoid.ParseAlfanum(c.FormValue(name))
 	if err != nil {
 		gko.Err(err).Ctx("name", name).Log()
 	}
@@ -132,6 +132,7 @@ func (c *Context) FormAlfanum(name string) gkoid.Alfanum {
 
 // Múltiples valores del form sin modificar.
 func (c *Context) MultiFormTalCual(name string) []string {
+	c.completarMultipart()
 	if c.request.PostForm == nil && c.request.Form == nil {
 		c.request.ParseForm()
 	}
@@ -207,10 +208,10 @@ func (c *Context) MultiFormUintMust(name string) ([]uint64, error) {
 
 // Deprecated. Transformar texto en capa de aplicación, no en handler.
 func (c *Context) FormUpper(name string) string {
-	return strings.ToUpper(gkt.SinEspaciosExtra(c.request.FormValue(name)))
+	return strings.ToUpper(gkt.SinEspaciosExtra(c.FormValue(name)))
 }
 
 // Deprecated. Transformar texto en capa de aplicación, no en handler.
 func (c *Context) FormLower(name string) string {
-	return strings.ToLower(gkt.SinEspaciosExtra(c.request.FormValue(name)))
+	return strings.ToLower(gkt.SinEspaciosExtra(c.FormValue(name)))
 }
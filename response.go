@@ -19,6 +19,7 @@ type Response struct {
 	Status      int
 	Size        uint64
 	Committed   bool
+	compresor   compresor // Activado en WriteHeader si se negoció compresión.
 }
 
 // NewResponse creates a new instance of Response.
@@ -75,8 +76,12 @@ func (r *Response) Write(b []byte) (n int, err error) {
 		}
 		r.WriteHeader(r.Status)
 	}
-	n, err = r.Writer.Write(b)
-	r.Size += uint64(n)
+	if r.compresor != nil {
+		n, err = r.compresor.Write(b) // Size se cuenta al escribir comprimido.
+	} else {
+		n, err = r.Writer.Write(b)
+		r.Size += uint64(n)
+	}
 	for _, fn := range r.afterFuncs {
 		fn()
 	}
@@ -87,6 +92,9 @@ func (r *Response) Write(b []byte) (n int, err error) {
 // buffered data to the client.
 // See [http.Flusher](https://golang.org/pkg/net/http/#Flusher)
 func (r *Response) Flush() {
+	if r.compresor != nil {
+		r.compresor.Flush()
+	}
 	r.Writer.(http.Flusher).Flush()
 }
 
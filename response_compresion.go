@@ -0,0 +1,190 @@
+package gecko
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ================================================================ //
+// ========== COMPRESIÓN ========================================== //
+
+// Bodies con Content-Length menor a esto no se comprimen porque
+// el ahorro no compensa el header y el trabajo extra.
+const minimoComprimir = 1024
+
+// Compresor que además permite enviar lo acumulado con Flush.
+type compresor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+var (
+	poolGzip = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}}
+	poolFlate = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+		return w
+	}}
+)
+
+// Se registra como Response.Before al crear el contexto para decidir,
+// justo antes de enviar los headers, si la respuesta se comprime.
+//
+// Se comprime cuando está activado con g.Comprimir o c.Compress, el
+// cliente lo acepta en Accept-Encoding, el MIME es de texto y el body
+// no es muy pequeño. Los rangos, HEAD y respuestas sin body se omiten.
+func (c *Context) negociarCompresión() {
+	if !c.Compress && !c.gecko.Comprimir {
+		return
+	}
+	r := c.response
+	h := r.Header()
+	if r.Status < 200 || r.Status == 204 || r.Status == 206 || r.Status == 304 {
+		return
+	}
+	if c.request.Method == "HEAD" || h.Get(HeaderContentEncoding) != "" || h.Get("Content-Range") != "" {
+		return
+	}
+	if !mimeComprimible(h.Get(HeaderContentType)) {
+		return
+	}
+	// La respuesta cambia según Accept-Encoding aunque este cliente no comprima.
+	if !headerContieneToken(h, HeaderVary, HeaderAcceptEncoding) {
+		h.Add(HeaderVary, HeaderAcceptEncoding)
+	}
+	if largo, err := strconv.Atoi(h.Get(HeaderContentLength)); err == nil && largo < minimoComprimir {
+		return
+	}
+	encoding := negociarEncoding(c.request.Header.Get(HeaderAcceptEncoding))
+	if encoding == "" {
+		return
+	}
+
+	h.Set(HeaderContentEncoding, encoding)
+	h.Del(HeaderContentLength)
+	// El ETag fuerte identifica los bytes sin comprimir.
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+
+	destino := escritorDirecto{r}
+	switch encoding {
+	case "gzip":
+		gz := poolGzip.Get().(*gzip.Writer)
+		gz.Reset(destino)
+		r.compresor = gz
+	case "deflate":
+		fl := poolFlate.Get().(*flate.Writer)
+		fl.Reset(destino)
+		r.compresor = fl
+	}
+}
+
+// Escribe los bytes comprimidos al cliente contándolos para el log.
+type escritorDirecto struct {
+	r *Response
+}
+
+func (e escritorDirecto) Write(b []byte) (int, error) {
+	n, err := e.r.Writer.Write(b)
+	e.r.Size += uint64(n)
+	return n, err
+}
+
+// Termina de enviar lo comprimido y devuelve el compresor al pool.
+// Se llama al terminar la solicitud.
+func (r *Response) cerrarCompresor() error {
+	if r.compresor == nil {
+		return nil
+	}
+	err := r.compresor.Close()
+	switch w := r.compresor.(type) {
+	case *gzip.Writer:
+		w.Reset(nil)
+		poolGzip.Put(w)
+	case *flate.Writer:
+		w.Reset(nil)
+		poolFlate.Put(w)
+	}
+	r.compresor = nil
+	return err
+}
+
+// Elige gzip o deflate según Accept-Encoding respetando q=0.
+// Prefiere gzip cuando ambos tienen la misma calidad.
+func negociarEncoding(acceptEncoding string) string {
+	calidades := calidadesEncoding(acceptEncoding)
+	gz, df := calidades["gzip"], calidades["deflate"]
+	switch {
+	case gz > 0 && gz >= df:
+		return "gzip"
+	case df > 0:
+		return "deflate"
+	}
+	return ""
+}
+
+// Calidad (q) que el cliente da a cada encoding en Accept-Encoding.
+// Los que no menciona tienen la de "*" si la incluye, o cero.
+func calidadesEncoding(acceptEncoding string) map[string]float64 {
+	calidades := map[string]float64{}
+	comodín, hayComodín := 0.0, false
+	for _, parte := range strings.Split(acceptEncoding, ",") {
+		nombre, params, _ := strings.Cut(strings.TrimSpace(parte), ";")
+		nombre = strings.ToLower(strings.TrimSpace(nombre))
+		if nombre == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if nombre == "*" {
+			comodín, hayComodín = q, true
+			continue
+		}
+		calidades[nombre] = q
+	}
+	if hayComodín {
+		for _, enc := range []string{"br", "gzip", "deflate"} {
+			if _, ok := calidades[enc]; !ok {
+				calidades[enc] = comodín
+			}
+		}
+	}
+	return calidades
+}
+
+// Solo se comprimen formatos de texto. Imágenes, video, audio, fuentes
+// woff/woff2, zip, pdf, etc. ya vienen comprimidos. Tampoco los streams
+// de eventos porque cada evento debe llegar de inmediato.
+func mimeComprimible(contentType string) bool {
+	mimeType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mimeType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mimeType, "text/"):
+		return true
+	case strings.HasSuffix(mimeType, "+json"), strings.HasSuffix(mimeType, "+xml"):
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/javascript", "application/xml",
+		"application/x-javascript", "application/wasm", "application/manifest+json",
+		"image/svg+xml", "image/x-icon", "font/ttf", "font/otf":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package gecko
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegociarEncoding(t *testing.T) {
+	casos := map[string]string{
+		"":                                 "",
+		"gzip":                             "gzip",
+		"GZIP":                             "gzip",
+		"deflate":                          "deflate",
+		"deflate, gzip":                    "gzip",
+		"gzip;q=0.8, deflate;q=0.8":        "gzip",
+		"gzip;q=0.5, deflate":              "deflate",
+		"gzip;q=0, deflate;q=0":            "",
+		"gzip;q=0":                         "",
+		"br, identity":                     "",
+		"*":                                "gzip",
+		"*;q=0.5, gzip;q=0":                "deflate",
+		"br;q=1.0, gzip;q=0.8, *;q=0.1":    "gzip",
+		" deflate ; q=0.9 , gzip ; q=0.1 ": "deflate",
+	}
+	for accept, esperado := range casos {
+		if enc := negociarEncoding(accept); enc != esperado {
+			t.Errorf("Accept-Encoding %q: %q, se esperaba %q", accept, enc, esperado)
+		}
+	}
+}
+
+// Descomprime el body de la respuesta según su Content-Encoding.
+func descomprimir(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var r io.Reader = rec.Body
+	switch rec.Header().Get(HeaderContentEncoding) {
+	case "gzip":
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	case "deflate":
+		r = flate.NewReader(rec.Body)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompresion(t *testing.T) {
+	texto := strings.Repeat("gecko comprime texto. ", 100)
+	g := New()
+	g.Comprimir = true
+	g.GET("/texto", func(c *Context) error {
+		c.response.Header().Set("ETag", `"v1"`)
+		return c.StringOk(texto)
+	})
+	g.GET("/chico", func(c *Context) error { return c.StringOk("hola") })
+	g.GET("/json", func(c *Context) error { return c.JSON(http.StatusOK, map[string]string{"texto": texto}) })
+	g.GET("/png", func(c *Context) error { return c.ContentOk("image/png", []byte(texto)) })
+	g.GET("/rango", func(c *Context) error {
+		c.response.Header().Set("Content-Range", "bytes 0-1999/5000")
+		return c.Blob(http.StatusPartialContent, MIMETextPlainCharsetUTF8, []byte(texto[:2000]))
+	})
+
+	casos := []struct {
+		nombre   string
+		método   string
+		ruta     string
+		accept   string
+		encoding string
+		vary     bool
+	}{
+		{"gzip", http.MethodGet, "/texto", "gzip, deflate", "gzip", true},
+		{"deflate por q", http.MethodGet, "/texto", "gzip;q=0.2, deflate", "deflate", true},
+		{"sin Accept-Encoding", http.MethodGet, "/texto", "", "", true},
+		{"gzip rechazado", http.MethodGet, "/texto", "gzip;q=0", "", true},
+		{"body chico", http.MethodGet, "/chico", "gzip", "", true},
+		{"json en streaming", http.MethodGet, "/json", "gzip", "gzip", true},
+		{"MIME binario", http.MethodGet, "/png", "gzip", "", false},
+		{"rango", http.MethodGet, "/rango", "gzip", "", false},
+		{"HEAD", http.MethodHead, "/texto", "gzip", "", false},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(caso.método, caso.ruta, nil)
+		if caso.accept != "" {
+			req.Header.Set(HeaderAcceptEncoding, caso.accept)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		h := rec.Header()
+		if h.Get(HeaderContentEncoding) != caso.encoding {
+			t.Errorf("%s: Content-Encoding %q, se esperaba %q", caso.nombre, h.Get(HeaderContentEncoding), caso.encoding)
+		}
+		if vary := h.Get(HeaderVary) == HeaderAcceptEncoding; vary != caso.vary {
+			t.Errorf("%s: Vary %q", caso.nombre, h.Get(HeaderVary))
+		}
+		if caso.encoding != "" && h.Get(HeaderContentLength) != "" {
+			t.Errorf("%s: Content-Length %q en respuesta comprimida", caso.nombre, h.Get(HeaderContentLength))
+		}
+		if caso.encoding == "" && caso.método == http.MethodGet && caso.ruta == "/texto" &&
+			h.Get(HeaderContentLength) != "2200" {
+			t.Errorf("%s: Content-Length %q sin comprimir", caso.nombre, h.Get(HeaderContentLength))
+		}
+	}
+
+	// El contenido descomprimido es el original y el ETag se vuelve débil.
+	for _, accept := range []string{"gzip", "deflate"} {
+		req := httptest.NewRequest(http.MethodGet, "/texto", nil)
+		req.Header.Set(HeaderAcceptEncoding, accept)
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Body.Len() >= len(texto) || descomprimir(t, rec) != texto {
+			t.Errorf("%s: %d bytes comprimidos con contenido distinto", accept, rec.Body.Len())
+		}
+		if rec.Header().Get("ETag") != `W/"v1"` {
+			t.Errorf("%s: ETag %q", accept, rec.Header().Get("ETag"))
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	var datos map[string]string
+	if err := json.NewDecoder(bytes.NewBufferString(descomprimir(t, rec))).Decode(&datos); err != nil || datos["texto"] != texto {
+		t.Errorf("json comprimido: %v", err)
+	}
+
+	// Sin g.Comprimir solo se comprime con c.Compress.
+	g.Comprimir = false
+	req = httptest.NewRequest(http.MethodGet, "/texto", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Header().Get(HeaderContentEncoding) != "" || rec.Body.String() != texto {
+		t.Errorf("sin g.Comprimir: Content-Encoding %q", rec.Header().Get(HeaderContentEncoding))
+	}
+}
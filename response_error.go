@@ -21,13 +21,17 @@ func (g *Gecko) responderErrorHTTP(c *Context, err error) {
 		gko.LogAlertf("gko.ErrHandler: context nil: %v", err)
 		return
 	}
+	if c.response.Status == http.StatusSwitchingProtocols {
+		gko.Err(err).Op(c.path).Log() // WebSocket terminado con error.
+		return
+	}
 	if c.response.Committed {
 		gko.LogAlertf("gko.ErrHandler: err returned after response: %s %s", c.path, err)
 		return
 	}
 
 	// Copiar error y agregar contexto http para loggearlo sin agregar info redundante a log http.
-	gkerr := *gko.Err(err)
+	gkerr := *gko.Err(errorBody(err))
 	gkerr.Op(c.path) // Patrón de ruta registrada para ubicar handler.
 	if strings.Contains(c.path, "}") {
 		gkerr.Ctx("path", c.request.URL.Path) // Si hay parámetros en la ruta se incluyen.
@@ -48,6 +52,15 @@ func (g *Gecko) responderErrorHTTP(c *Context, err error) {
 		return
 	}
 
+	// Clientes de API reciben el error en JSON.
+	if c.Formato() == FormatoJSON {
+		err = c.responderErrorJSON(&gkerr)
+		if err != nil {
+			gko.LogAlert("gko.ErrHandler: json response: " + err.Error())
+		}
+		return
+	}
+
 	// HTMX solo necesita un string.
 	if c.EsHTMX() {
 		err = c.String(gkerr.GetCodigoHTTP(), gkerr.GetMensaje())
@@ -64,9 +77,7 @@ func (g *Gecko) responderErrorHTTP(c *Context, err error) {
 			"StatusCode": gkerr.GetCodigoHTTP(),
 			"Titulo":     "Ups: " + gkerr.GetMensaje(),
 		}
-		if c.Sesion != nil {
-			data["Sesion"] = c.Sesion
-		}
+		c.agregarDatosSesion(data)
 		err = c.Render(gkerr.GetCodigoHTTP(), g.TmplError, data)
 		if err != nil {
 			gko.LogAlert("gko.ErrHandler: render err: " + err.Error())
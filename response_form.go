@@ -0,0 +1,120 @@
+package gecko
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== FORMULARIO INVÁLIDO ================================= //
+
+// Vuelve a renderizar la plantilla del formulario cuando err trae mensajes
+// por campo (ver gko.Error.Campo) para que el usuario no pierda lo que
+// escribió. Responde con status 422 y agrega a los datos:
+//
+//   - "Errores": map[string]string campo → mensaje.
+//   - "Valores": map[string]string campo → primer valor recibido.
+//   - "Mensaje": mensaje general del error.
+//
+// A HTMX le manda solo el parcial con HX-Retarget: closest form y HX-Reswap:
+// outerHTML para reemplazar el formulario que contiene al elemento que hizo
+// la solicitud, aunque haya sido un botón o un input con hx-post, por lo que
+// la plantilla debe contener el elemento <form> completo. Al navegador le manda la página
+// completa con el layout.
+//
+// Si err no tiene mensajes por campo se devuelve tal cual para que lo
+// maneje el error handler centralizado.
+func (c *Context) RenderFormInvalido(name string, data map[string]any, err error) error {
+	if err == nil {
+		return nil
+	}
+	gkerr := gko.Err(err)
+	if !gkerr.TieneCampos() {
+		return err
+	}
+	if c.gecko.Renderer == nil {
+		return gko.ErrNoDisponible.Str("gecko: renderer nulo")
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	valores, errForm := c.valoresFormulario()
+	if errForm != nil {
+		return errForm
+	}
+	c.agregarDatosSesion(data)
+	data["Errores"] = gkerr.GetCampos()
+	data["Valores"] = valores
+	data["Mensaje"] = gkerr.GetMensaje()
+
+	// Registrar el error sin el contexto que agrega responderErrorHTTP
+	// porque el usuario sí recibe una respuesta útil.
+	gko.LogWarnf("form inválido %s: %s", c.path, gkerr.Error())
+
+	if c.EsHTMX() { // Enviar solo parcial a HTMX
+		data["EsHTMX"] = true
+		buf := new(bytes.Buffer)
+		err := c.gecko.Renderer.Render(buf, name, data, c)
+		if err != nil {
+			return err
+		}
+		c.response.Header().Add("Cache-Control", "no-store")
+		c.response.Header().Set("HX-Retarget", "closest form")
+		c.response.Header().Set("HX-Reswap", "outerHTML")
+		return c.HTMLBlob(http.StatusUnprocessableEntity, buf.Bytes())
+
+	} else { // Enviar encapsulado en layout HTML a navegador.
+		return c.renderConLayout(http.StatusUnprocessableEntity, name, data)
+	}
+}
+
+// Middleware para rutas de formularios que vuelve a renderizar la plantilla
+// "name" con los errores por campo cuando el handler devuelve un gko.Error
+// que los tenga. Cualquier otro error continúa al error handler.
+//
+// La función datos, si no es nil, da lo demás que necesita la plantilla
+// como las opciones de un select o la entidad que se está editando.
+//
+//	g.POS("/usuarios/{id}", s.postUsuario, gecko.ReRenderFormInvalido("usuario_form", s.datosFormUsuario))
+func ReRenderFormInvalido(name string, datos func(c *Context) (map[string]any, error)) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			if err == nil || c.response.Committed || !gko.Err(err).TieneCampos() {
+				return err
+			}
+			var data map[string]any
+			if datos != nil {
+				var errDatos error
+				data, errDatos = datos(c)
+				if errDatos != nil {
+					return gko.Err(errDatos).Op("ReRenderFormInvalido")
+				}
+			}
+			return c.RenderFormInvalido(name, data, err)
+		}
+	}
+}
+
+// Primer valor de cada campo recibido en el formulario y el query,
+// sin incluir el token CSRF.
+func (c *Context) valoresFormulario() (map[string]string, error) {
+	valores := map[string]string{}
+	form := c.request.Form
+	if form == nil || c.multipart != nil {
+		var err error
+		form, err = c.FormParams()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for campo, vals := range form {
+		if campo == CampoCSRF || len(vals) == 0 {
+			continue
+		}
+		valores[campo] = vals[0]
+	}
+	return valores, nil
+}
@@ -0,0 +1,128 @@
+package gecko
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// Renderer que escribe el nombre de la plantilla y los datos del formulario.
+type rendererForm struct{}
+
+func (rendererForm) Render(w io.Writer, name string, data any, c *Context) error {
+	m := data.(map[string]any)
+	if name == "base_layout" {
+		_, err := fmt.Fprintf(w, "<layout>%v</layout>", m["Contenido"])
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s errores=%v valores=%v mensaje=%v opciones=%v htmx=%v",
+		name, m["Errores"], m["Valores"], m["Mensaje"], m["Opciones"], m["EsHTMX"] != nil)
+	return err
+}
+
+func TestRenderFormInvalido(t *testing.T) {
+	g := New()
+	g.Renderer = rendererForm{}
+	errCampos := gko.ErrDatoInvalido.Msg("Revisa los datos").Campo("nombre", "Falta el nombre")
+	g.POST("/campos", func(c *Context) error { return c.RenderFormInvalido("form", nil, errCampos) })
+	g.POST("/sin-campos", func(c *Context) error {
+		return c.RenderFormInvalido("form", nil, gko.ErrNoEncontrado.Msg("No existe"))
+	})
+	g.POST("/sin-error", func(c *Context) error {
+		if err := c.RenderFormInvalido("form", nil, nil); err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	form := url.Values{"nombre": {""}, "edad": {"5", "6"}, CampoCSRF: {"token"}}
+
+	// Navegador: página completa con el layout.
+	rec := solicitudCSRF(g, postForm("/campos", form))
+	esperado := "<layout>form errores=map[nombre:Falta el nombre] valores=map[edad:5 nombre:] mensaje=Revisa los datos. opciones=<nil> htmx=false</layout>"
+	if rec.Code != http.StatusUnprocessableEntity || rec.Body.String() != esperado {
+		t.Errorf("navegador: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	// HTMX: solo el formulario para reemplazar el más cercano.
+	req := postForm("/campos", form)
+	req.Header.Set("HX-Request", "true")
+	rec = solicitudCSRF(g, req)
+	if rec.Code != http.StatusUnprocessableEntity || strings.HasPrefix(rec.Body.String(), "<layout>") ||
+		!strings.HasSuffix(rec.Body.String(), "htmx=true") {
+		t.Errorf("htmx: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	for header, valor := range map[string]string{
+		"HX-Retarget":   "closest form",
+		"HX-Reswap":     "outerHTML",
+		"Cache-Control": "no-store",
+	} {
+		if rec.Header().Get(header) != valor {
+			t.Errorf("htmx: header %s %q, se esperaba %q", header, rec.Header().Get(header), valor)
+		}
+	}
+
+	// Sin mensajes por campo el error sigue al error handler.
+	rec = solicitudCSRF(g, postForm("/sin-campos", form))
+	if rec.Code != http.StatusNotFound || strings.Contains(rec.Body.String(), "errores=") {
+		t.Errorf("sin campos: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if rec := solicitudCSRF(g, postForm("/sin-error", form)); rec.Code != http.StatusOK {
+		t.Errorf("sin error: status %d", rec.Code)
+	}
+}
+
+func TestReRenderFormInvalido(t *testing.T) {
+	g := New()
+	g.Renderer = rendererForm{}
+	datos := func(c *Context) (map[string]any, error) {
+		if c.FormValue("falla") != "" {
+			return nil, gko.ErrAlLeer.Msg("No se cargaron las opciones")
+		}
+		return map[string]any{"Opciones": []string{"a", "b"}}, nil
+	}
+	g.POST("/form", func(c *Context) error {
+		var args struct {
+			Nombre string `form:"nombre" label:"Nombre" validate:"required"`
+		}
+		if err := c.Bind(&args); err != nil {
+			return err
+		}
+		if args.Nombre == "existe" {
+			return gko.ErrYaExiste.Msg("Ya existe")
+		}
+		if args.Nombre == "escrito" {
+			c.StringOk("escrito")
+			return gko.ErrDatoInvalido.Campo("nombre", "Tarde")
+		}
+		return c.StringOk("guardado")
+	}, ReRenderFormInvalido("form", datos))
+
+	casos := []struct {
+		nombre string
+		form   url.Values
+		status int
+		body   string
+	}{
+		{"válido", url.Values{"nombre": {"Ana"}}, http.StatusOK, "guardado"},
+		{"inválido", url.Values{"nombre": {""}}, http.StatusUnprocessableEntity,
+			"form errores=map[nombre:Nombre es obligatorio] valores=map[nombre:] mensaje=Nombre es obligatorio. opciones=[a b] htmx=true"},
+		{"error sin campos", url.Values{"nombre": {"existe"}}, http.StatusConflict, ""},
+		{"datos con error", url.Values{"nombre": {""}, "falla": {"1"}}, http.StatusServiceUnavailable, "No se cargaron las opciones."},
+		{"respuesta ya enviada", url.Values{"nombre": {"escrito"}}, http.StatusOK, "escrito"},
+	}
+	for _, caso := range casos {
+		req := postForm("/form", caso.form)
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status || (caso.body != "" && rec.Body.String() != caso.body) {
+			t.Errorf("%s: status %d, body %q", caso.nombre, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -2,7 +2,6 @@ package gecko
 
 import (
 	"bytes"
-	"compress/gzip"
 	"html/template"
 	"io"
 	"net/http"
@@ -26,21 +25,7 @@ func (c *Context) Render(code int, name string, data any) error {
 	if err != nil {
 		return err
 	}
-
-	if c.Compress {
-		compressedBuf := new(bytes.Buffer)
-		gzipWriter := gzip.NewWriter(compressedBuf)
-		_, err = gzipWriter.Write(buf.Bytes())
-		if err != nil {
-			return err
-		}
-		gzipWriter.Close()
-		c.response.Header().Set("Content-Encoding", "gzip")
-		return c.HTMLBlob(http.StatusOK, compressedBuf.Bytes())
-
-	} else {
-		return c.HTMLBlob(code, buf.Bytes())
-	}
+	return c.HTMLBlob(code, buf.Bytes())
 }
 
 // Renderizar una plantilla registrada en gecko.Renderer bajo "name"
@@ -55,9 +40,7 @@ func (c *Context) RenderOk(name string, data map[string]any) error {
 	if data == nil {
 		data = map[string]any{}
 	}
-	if c.Sesion != nil {
-		data["Sesion"] = c.Sesion
-	}
+	c.agregarDatosSesion(data)
 
 	if c.EsHTMX() { // Enviar solo parcial a HTMX
 		data["EsHTMX"] = true
@@ -67,52 +50,29 @@ func (c *Context) RenderOk(name string, data map[string]any) error {
 			return err
 		}
 		c.response.Header().Add("Cache-Control", "no-store") // No guardar en ningún caché. HTMX se encarga con hx-push-url.
-
-		if c.Compress {
-			compressedBuf := new(bytes.Buffer)
-			gzipWriter := gzip.NewWriter(compressedBuf)
-			_, err = gzipWriter.Write(buf.Bytes())
-			if err != nil {
-				return err
-			}
-			gzipWriter.Close()
-			c.response.Header().Set("Content-Encoding", "gzip")
-			return c.HTMLBlob(http.StatusOK, compressedBuf.Bytes())
-
-		} else {
-			return c.HTMLBlob(http.StatusOK, buf.Bytes())
-		}
+		return c.HTMLBlob(http.StatusOK, buf.Bytes())
 
 	} else { // Enviar encapsulado en layout HTML a navegador.
+		return c.renderConLayout(http.StatusOK, name, data)
+	}
+}
 
-		c.response.Header().Add("Cache-Control", "no-store") // TODO: configurable
-
-		buf0 := new(bytes.Buffer)
-		err := c.gecko.Renderer.Render(buf0, name, data, c)
-		if err != nil {
-			return err
-		}
-		data["Contenido"] = template.HTML(buf0.String())
-		buf := new(bytes.Buffer)
-		err = c.gecko.Renderer.Render(buf, c.gecko.TmplBaseLayout, data, c)
-		if err != nil {
-			return err
-		}
-		if c.Compress {
-			compressedBuf := new(bytes.Buffer)
-			gzipWriter := gzip.NewWriter(compressedBuf)
-			_, err = gzipWriter.Write(buf.Bytes())
-			if err != nil {
-				return err
-			}
-			gzipWriter.Close()
-			c.response.Header().Set("Content-Encoding", "gzip")
-			return c.HTMLBlob(http.StatusOK, compressedBuf.Bytes())
+// Renderiza la plantilla dentro del layout como página HTML completa.
+func (c *Context) renderConLayout(code int, name string, data map[string]any) error {
+	c.response.Header().Add("Cache-Control", "no-store") // TODO: configurable
 
-		} else {
-			return c.HTMLBlob(http.StatusOK, buf.Bytes())
-		}
+	buf0 := new(bytes.Buffer)
+	err := c.gecko.Renderer.Render(buf0, name, data, c)
+	if err != nil {
+		return err
+	}
+	data["Contenido"] = template.HTML(buf0.String())
+	buf := new(bytes.Buffer)
+	err = c.gecko.Renderer.Render(buf, c.gecko.TmplBaseLayout, data, c)
+	if err != nil {
+		return err
 	}
+	return c.HTMLBlob(code, buf.Bytes())
 }
 
 // ================================================================ //
@@ -126,9 +86,7 @@ func (c *Context) RenderContenido(name string, data map[string]any) error {
 	if data == nil {
 		data = map[string]any{}
 	}
-	if c.Sesion != nil {
-		data["Sesion"] = c.Sesion
-	}
+	c.agregarDatosSesion(data)
 
 	if c.EsHTMX() { // Enviar solo parcial a HTMX
 
@@ -169,9 +127,7 @@ func (c *Context) RenderCard(name string, data map[string]any) error {
 	if data == nil {
 		data = map[string]any{}
 	}
-	if c.Sesion != nil {
-		data["Sesion"] = c.Sesion
-	}
+	c.agregarDatosSesion(data)
 
 	c.response.Header().Add("Cache-Control", "no-store") // ningún caché
 
@@ -241,3 +197,14 @@ func (c *Context) RenderCard(name string, data map[string]any) error {
 
 // ================================================================ //
 // ================================================================ //
+
+// Agrega a los datos de la plantilla la sesión y el token CSRF
+// para que estén disponibles en el layout y los formularios.
+func (c *Context) agregarDatosSesion(data map[string]any) {
+	if c.Sesion != nil {
+		data["Sesion"] = c.Sesion
+	}
+	if c.csrf != "" {
+		data["CSRFToken"] = c.csrf
+	}
+}
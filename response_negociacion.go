@@ -0,0 +1,118 @@
+package gecko
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== NEGOCIACIÓN DE CONTENIDO ============================ //
+
+// Formatos de respuesta que puede pedir el cliente. Ver c.Formato().
+const (
+	FormatoHTML = "html" // Página completa con layout.
+	FormatoHTMX = "htmx" // Solo la plantilla parcial.
+	FormatoJSON = "json" // Los datos sin plantilla.
+)
+
+// Formato en el que el cliente prefiere la respuesta:
+//
+//  1. El query param "format" si es "json", "html" o "htmx".
+//  2. FormatoHTMX si la solicitud es de HTMX.
+//  3. FormatoJSON si el Accept prefiere JSON sobre HTML.
+//  4. FormatoHTML en cualquier otro caso.
+func (c *Context) Formato() string {
+	switch f := strings.ToLower(c.QueryParam("format")); f {
+	case FormatoJSON, FormatoHTML, FormatoHTMX:
+		return f
+	}
+	if c.EsHTMX() {
+		return FormatoHTMX
+	}
+	if prefiereJSON(c.request.Header.Get(HeaderAccept)) {
+		return FormatoJSON
+	}
+	return FormatoHTML
+}
+
+// Responde con los mismos datos en el formato que prefiera el cliente
+// para que la UI y una API compartan el mismo handler:
+//
+//   - HTMX: la plantilla sola.
+//   - Navegador: la plantilla dentro del layout.
+//   - API: JSON de data, sin los datos de la sesión.
+//
+// Ver c.Formato() para saber cómo se decide.
+func (c *Context) Respond(name string, data map[string]any) error {
+	c.response.Header().Add(HeaderVary, HeaderAccept)
+	if data == nil {
+		data = map[string]any{}
+	}
+	formato := c.Formato()
+	if formato == FormatoJSON {
+		return c.JSON(http.StatusOK, data)
+	}
+	if c.gecko.Renderer == nil {
+		return gko.ErrNoDisponible.Str("gecko: renderer nulo")
+	}
+	c.agregarDatosSesion(data)
+	if formato == FormatoHTMX {
+		data["EsHTMX"] = true
+		c.response.Header().Add(HeaderCacheControl, "no-store")
+		return c.Render(http.StatusOK, name, data)
+	}
+	return c.renderConLayout(http.StatusOK, name, data)
+}
+
+// Error como se envía en JSON a los clientes de una API.
+//
+//	{"error": {"clave": "not_found", "mensaje": "...", "status": 404}}
+type ErrorJSON struct {
+	Clave   string            `json:"clave"`
+	Mensaje string            `json:"mensaje"`
+	Status  int               `json:"status"`
+	Campos  map[string]string `json:"campos,omitempty"` // Ver gko.Error.Campo().
+}
+
+func (c *Context) responderErrorJSON(gkerr *gko.Error) error {
+	return c.JSON(gkerr.GetCodigoHTTP(), map[string]ErrorJSON{
+		"error": {
+			Clave:   string(gkerr.ErrorKey()),
+			Mensaje: gkerr.GetMensaje(),
+			Status:  gkerr.GetCodigoHTTP(),
+			Campos:  gkerr.GetCampos(),
+		},
+	})
+}
+
+// Reporta si en el Accept el JSON tiene más calidad que el HTML.
+// Un Accept vacío o "*/*" prefiere HTML.
+func prefiereJSON(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	qJSON, qHTML := 0.0, 0.0
+	for _, parte := range strings.Split(accept, ",") {
+		tipo, params, err := mime.ParseMediaType(strings.TrimSpace(parte))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		switch {
+		case tipo == "application/json", strings.HasSuffix(tipo, "+json"):
+			qJSON = max(qJSON, q)
+		case tipo == "text/html", tipo == "application/xhtml+xml", tipo == "*/*", tipo == "text/*":
+			qHTML = max(qHTML, q)
+		}
+	}
+	return qJSON > qHTML
+}
@@ -0,0 +1,65 @@
+package gecko
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormato(t *testing.T) {
+	casos := []struct {
+		nombre string
+		query  string
+		htmx   bool
+		accept string
+		want   string
+	}{
+		{"navegador", "", false, "text/html,application/xhtml+xml,*/*;q=0.8", FormatoHTML},
+		{"sin Accept", "", false, "", FormatoHTML},
+		{"API", "", false, "application/json", FormatoJSON},
+		{"HTMX", "", true, "text/html", FormatoHTMX},
+		{"HTMX antes que Accept", "", true, "application/json", FormatoHTMX},
+		{"query antes que HTMX", "?format=json", true, "text/html", FormatoJSON},
+		{"query antes que Accept", "?format=html", false, "application/json", FormatoHTML},
+		{"query en mayúsculas", "?format=HTMX", false, "", FormatoHTMX},
+		{"query desconocido", "?format=xml", false, "application/json", FormatoJSON},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(http.MethodGet, "/"+caso.query, nil)
+		if caso.htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		if caso.accept != "" {
+			req.Header.Set(HeaderAccept, caso.accept)
+		}
+		c := New().nuevoContext(httptest.NewRecorder(), req, "GET /")
+		if got := c.Formato(); got != caso.want {
+			t.Errorf("%s: Formato() = %q, se esperaba %q", caso.nombre, got, caso.want)
+		}
+	}
+}
+
+func TestPrefiereJSON(t *testing.T) {
+	casos := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", true},
+		{"application/problem+json", true},
+		{"application/json, text/html", false},             // Empate: HTML.
+		{"application/json;q=0.5, text/html;q=0.5", false}, // Empate con q.
+		{"application/json, */*;q=0.1", true},
+		{"text/html;q=0.9, application/json", true},
+		{"application/json;q=0.8, text/*;q=0.9", false},
+		{"application/json;q=0, text/plain", false},
+		{"application/json;q=x, text/html;q=0.5", true}, // q inválido cuenta como 1.
+		{"text/plain, application/json;q=0.1", true},    // text/plain no es HTML.
+	}
+	for _, caso := range casos {
+		if got := prefiereJSON(caso.accept); got != caso.want {
+			t.Errorf("prefiereJSON(%q) = %v, se esperaba %v", caso.accept, got, caso.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gecko
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ================================================================ //
+// ========== RANGOS Y SOLICITUDES CONDICIONALES ================== //
+
+// Responde con el contenido del io.ReadSeeker atendiendo Range e
+// If-Range, incluso con varios rangos como multipart/byteranges, para
+// que se puedan reanudar descargas y adelantar videos.
+//
+// También responde 304 según If-None-Match e If-Modified-Since usando
+// el ETag que se haya puesto en la respuesta y el modTime dado, que
+// puede ser cero si no se conoce. Si contentType está vacío se deduce
+// del contenido.
+//
+//	c.Response().Header().Set("ETag", `"`+doc.Hash+`"`)
+//	return c.StreamSeeker("application/pdf", doc.Actualizado, bytes.NewReader(doc.PDF))
+func (c *Context) StreamSeeker(contentType string, modTime time.Time, contenido io.ReadSeeker) error {
+	if contentType != "" {
+		c.writeContentType(contentType)
+	}
+	// ServeContent usa el nombre solo para deducir el MIME por extensión.
+	http.ServeContent(c.response, c.request, "", modTime, contenido)
+	return nil
+}
+
+// Como StreamSeeker para contenido generado del que se conoce el tamaño
+// y se puede leer desde cualquier posición sin tenerlo completo.
+func (c *Context) StreamReaderAt(contentType string, modTime time.Time, contenido io.ReaderAt, tamaño int64) error {
+	return c.StreamSeeker(contentType, modTime, io.NewSectionReader(contenido, 0, tamaño))
+}
+
+// Reporta si la solicitud condicional GET o HEAD coincide con el ETag
+// o Last-Modified ya puestos en la respuesta, en cuyo caso se debe
+// responder 304 sin body. If-None-Match tiene prioridad.
+func (c *Context) noModificado() bool {
+	if c.request.Method != http.MethodGet && c.request.Method != http.MethodHead {
+		return false
+	}
+	h := c.response.Header()
+	if inm := c.request.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		return etag != "" && etagCoincide(inm, etag)
+	}
+	ims := c.request.Header.Get(HeaderIfModifiedSince)
+	lastMod := h.Get(HeaderLastModified)
+	if ims == "" || lastMod == "" {
+		return false
+	}
+	desde, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modificado, err := http.ParseTime(lastMod)
+	if err != nil {
+		return false
+	}
+	return !modificado.After(desde)
+}
+
+// Compara un If-None-Match, que puede tener varios ETags o "*",
+// con el ETag de la respuesta ignorando si son débiles (W/).
+func etagCoincide(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidato := range strings.Split(ifNoneMatch, ",") {
+		candidato = strings.TrimSpace(candidato)
+		if candidato == "*" || strings.TrimPrefix(candidato, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Responde 304 quitando los headers que describen el body.
+func (c *Context) responderNoModificado() error {
+	h := c.response.Header()
+	h.Del(HeaderContentType)
+	h.Del(HeaderContentLength)
+	h.Del(HeaderContentEncoding)
+	if h.Get("ETag") != "" {
+		h.Del(HeaderLastModified)
+	}
+	return c.NoContent(Status304NotModified)
+}
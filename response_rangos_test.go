@@ -0,0 +1,133 @@
+package gecko
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRangos(t *testing.T) {
+	const contenido = "0123456789"
+	modificado := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := New()
+	g.Filesystem = fstest.MapFS{"datos.txt": {Data: []byte(contenido), ModTime: modificado}}
+	g.GET("/seeker", func(c *Context) error {
+		c.Response().Header().Set("ETag", `"v1"`)
+		return c.StreamSeeker(MIMETextPlain, modificado, strings.NewReader(contenido))
+	})
+	g.GET("/reader-at", func(c *Context) error {
+		return c.StreamReaderAt(MIMETextPlain, time.Time{}, strings.NewReader(contenido), int64(len(contenido)))
+	})
+	g.GET("/stream", func(c *Context) error {
+		return c.Stream(http.StatusOK, MIMETextPlain, bytes.NewReader([]byte(contenido)))
+	})
+	g.GET("/stream-reader", func(c *Context) error {
+		return c.Stream(http.StatusOK, MIMETextPlain, io.MultiReader(strings.NewReader(contenido)))
+	})
+	g.GET("/adjunto", func(c *Context) error {
+		return c.FileAttachment("datos.txt", "reporte.txt")
+	})
+
+	casos := []struct {
+		nombre  string
+		ruta    string
+		headers map[string]string
+		status  int
+		body    string
+		rango   string // Content-Range esperado.
+	}{
+		{"completo", "/seeker", nil, http.StatusOK, contenido, ""},
+		{"un rango", "/seeker", map[string]string{"Range": "bytes=2-5"}, http.StatusPartialContent, "2345", "bytes 2-5/10"},
+		{"sufijo", "/seeker", map[string]string{"Range": "bytes=-3"}, http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"fuera de rango", "/seeker", map[string]string{"Range": "bytes=20-30"}, http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+		{"If-None-Match", "/seeker", map[string]string{"If-None-Match": `"v0", "v1"`}, http.StatusNotModified, "", ""},
+		{"If-None-Match débil", "/seeker", map[string]string{"If-None-Match": `W/"v1"`}, http.StatusNotModified, "", ""},
+		{"If-None-Match distinto", "/seeker", map[string]string{"If-None-Match": `"v0"`}, http.StatusOK, contenido, ""},
+		{"If-Modified-Since", "/seeker", map[string]string{"If-Modified-Since": modificado.Format(http.TimeFormat)}, http.StatusNotModified, "", ""},
+		{"If-Range vigente", "/seeker", map[string]string{"Range": "bytes=0-1", "If-Range": `"v1"`}, http.StatusPartialContent, "01", "bytes 0-1/10"},
+		{"If-Range viejo", "/seeker", map[string]string{"Range": "bytes=0-1", "If-Range": `"v0"`}, http.StatusOK, contenido, ""},
+		{"ReaderAt", "/reader-at", map[string]string{"Range": "bytes=8-"}, http.StatusPartialContent, "89", "bytes 8-9/10"},
+		{"Stream con ReadSeeker", "/stream", map[string]string{"Range": "bytes=0-0"}, http.StatusPartialContent, "0", "bytes 0-0/10"},
+		{"Stream sin ReadSeeker", "/stream-reader", map[string]string{"Range": "bytes=0-0"}, http.StatusOK, contenido, ""},
+		{"FileAttachment", "/adjunto", map[string]string{"Range": "bytes=5-6"}, http.StatusPartialContent, "56", "bytes 5-6/10"},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(http.MethodGet, caso.ruta, nil)
+		for k, v := range caso.headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status {
+			t.Errorf("%s: status %d, se esperaba %d", caso.nombre, rec.Code, caso.status)
+			continue
+		}
+		if caso.status != http.StatusRequestedRangeNotSatisfiable && rec.Body.String() != caso.body {
+			t.Errorf("%s: body %q", caso.nombre, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Range"); got != caso.rango {
+			t.Errorf("%s: Content-Range %q", caso.nombre, got)
+		}
+	}
+
+	// Varios rangos.
+	req := httptest.NewRequest(http.MethodGet, "/seeker", nil)
+	req.Header.Set("Range", "bytes=0-1,8-9")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusPartialContent || !strings.HasPrefix(rec.Header().Get(HeaderContentType), "multipart/byteranges") {
+		t.Errorf("varios rangos: status %d, Content-Type %q", rec.Code, rec.Header().Get(HeaderContentType))
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "\r\n01\r\n") || !strings.Contains(body, "\r\n89\r\n") {
+		t.Errorf("varios rangos: body %q", body)
+	}
+
+	// Adjunto.
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/adjunto", nil))
+	if got := rec.Header().Get(HeaderContentDisposition); got != `attachment; filename="reporte.txt"` {
+		t.Errorf("Content-Disposition %q", got)
+	}
+	if got := rec.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges %q", got)
+	}
+}
+
+func TestBlobNoModificado(t *testing.T) {
+	g := New()
+	g.GET("/blob", func(c *Context) error {
+		c.Response().Header().Set("ETag", `"abc"`)
+		return c.ContentOk(MIMETextPlain, []byte("hola"))
+	})
+	casos := []struct {
+		método      string
+		ifNoneMatch string
+		status      int
+		body        string
+	}{
+		{http.MethodGet, "", http.StatusOK, "hola"},
+		{http.MethodGet, `"abc"`, http.StatusNotModified, ""},
+		{http.MethodGet, "*", http.StatusNotModified, ""},
+		{http.MethodGet, `"otro"`, http.StatusOK, "hola"},
+		{http.MethodHead, `"abc"`, http.StatusNotModified, ""},
+	}
+	for _, caso := range casos {
+		req := httptest.NewRequest(caso.método, "/blob", nil)
+		if caso.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", caso.ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != caso.status || (caso.método == http.MethodGet && rec.Body.String() != caso.body) {
+			t.Errorf("%s If-None-Match %q: status %d body %q", caso.método, caso.ifNoneMatch, rec.Code, rec.Body.String())
+		}
+		if caso.status == http.StatusNotModified && rec.Header().Get(HeaderContentType) != "" {
+			t.Errorf("%s If-None-Match %q: 304 con Content-Type", caso.método, caso.ifNoneMatch)
+		}
+	}
+}
@@ -10,9 +10,7 @@ import (
 
 func (c *Context) StringOk(msg string) error {
 	c.response.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	c.response.WriteHeader(200)
-	c.response.Writer.Write([]byte(msg))
-	return nil
+	return c.Blob(200, MIMETextPlainCharsetUTF8, []byte(msg))
 }
 
 // String sends a string response with status code 200 OK.
@@ -28,7 +26,5 @@ func (c *Context) StatusOkf(format string, a ...any) (err error) {
 // Retorna un estatus 202 aceptado con el mensaje dado.
 func (c *Context) StatusAccepted(msg string) error {
 	c.response.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	c.response.WriteHeader(202)
-	_, err := c.response.Writer.Write([]byte(msg))
-	return err
+	return c.Blob(202, MIMETextPlainCharsetUTF8, []byte(msg))
 }
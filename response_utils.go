@@ -2,6 +2,9 @@ package gecko
 
 import (
 	"io"
+	"net/http"
+	"strconv"
+	"time"
 )
 
 // Agregar un header a la respuesta.
@@ -28,22 +31,33 @@ func (c *Context) String(code int, s string) (err error) {
 }
 
 func (c *Context) ContentOk(contentType string, content []byte) (err error) {
-	c.writeContentType(contentType)
-	c.response.WriteHeader(200)
-	_, err = c.response.Write(content)
-	return
+	return c.Blob(200, contentType, content)
 }
 
 // Responder con status code y MIME especificados. Ver gecko.MIME...
+//
+// Si antes se puso un ETag o Last-Modified en la respuesta y la solicitud
+// es condicional y coincide, se responde 304 sin body en lugar de 200.
 func (c *Context) Blob(code int, contentType string, b []byte) (err error) {
+	if code == http.StatusOK && c.noModificado() {
+		return c.responderNoModificado()
+	}
 	c.writeContentType(contentType)
+	c.response.Header().Set(HeaderContentLength, strconv.Itoa(len(b))) // Se quita si se comprime.
 	c.response.WriteHeader(code)
 	_, err = c.response.Write(b)
 	return
 }
 
 // Responder con status code y MIME especificados. Ver gecko.MIME...
+//
+// Con status 200 y un io.ReadSeeker como *os.File o *bytes.Reader
+// atiende Range y solicitudes condicionales igual que StreamSeeker.
+// Con otros readers no, para eso usar StreamReaderAt.
 func (c *Context) Stream(code int, contentType string, r io.Reader) (err error) {
+	if rs, ok := r.(io.ReadSeeker); ok && code == http.StatusOK {
+		return c.StreamSeeker(contentType, time.Time{}, rs)
+	}
 	c.writeContentType(contentType)
 	c.response.WriteHeader(code)
 	_, err = io.Copy(c.response, r)
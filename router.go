@@ -2,7 +2,10 @@ package gecko
 
 import (
 	"net/http"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pargomx/gecko/gko"
@@ -34,43 +37,177 @@ type HandlerFunc func(c *Context) error
 
 // Registrar una nueva ruta con un http.HandlerFunc
 // que prepare el gecko.Context y ejecute el gecko.HandlerFunc.
-func (g *Gecko) registrarRuta(método string, ruta string, handler HandlerFunc) {
+//
+// Los middlewares de la ruta se encadenan al registrarla y los
+// globales de g.Use() por fuera en la primera solicitud.
+func (g *Gecko) registrarRuta(método string, ruta string, handler HandlerFunc, mws ...MiddlewareFunc) *Ruta {
 	patrón := toMuxPattern(método, ruta)
+	rt := g.agregarRuta(método, patrón, handler)
+	cadena := g.conGlobales(encadenarMiddlewares(handler, mws))
 	g.mux.HandleFunc(patrón, func(w http.ResponseWriter, r *http.Request) {
-		c := &Context{
-			request:  r,
-			response: NewResponse(w, g),
-			path:     patrón,
-			gecko:    g,
-			time:     time.Now(),
+		c := g.nuevoContext(w, r, patrón)
+		if método == http.MethodOptions && g.manejarPreflightCORS(c) {
+			g.terminarSolicitud(c, nil) // Respondido sin el handler OPTIONS de la ruta.
+			return
 		}
-		err := handler(c)
-		if err != nil {
-			g.responderErrorHTTP(c, err)
+		if cors := rt.políticaCORS(); cors != nil {
+			cors.agregarHeaders(c)
 		}
-		if g.HTTPLogger != nil {
-			g.logHTTP(c, err)
+		err := c.limitarBody(rt)
+		if err == nil && rt.timeout > 0 {
+			err = g.ejecutarConTimeout(c, cadena(), rt.timeout)
+		} else if err == nil {
+			err = g.ejecutarHandler(c, cadena())
 		}
+		g.terminarSolicitud(c, err)
 	})
 	// fmt.Println("RUTA:", patrón)
+	return rt
 }
 
-// NotFound handler para rutas GET no registradas evitando usar el de *http.ServeMux.
-func (g *Gecko) registrarNotFoundHandler() {
-	g.mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
-		c := &Context{
-			request:  r,
-			response: NewResponse(w, g),
-			path:     "GET /{...}",
-			gecko:    g,
-			time:     time.Now(),
+// Contexto para la solicitud con el patrón de ruta que la atiende.
+func (g *Gecko) nuevoContext(w http.ResponseWriter, r *http.Request, patrón string) *Context {
+	c := &Context{
+		request:  r,
+		response: NewResponse(w, g),
+		path:     patrón,
+		gecko:    g,
+		time:     time.Now(),
+	}
+	c.response.Before(c.negociarCompresión)
+	return c
+}
+
+// Compone el handler con los middlewares globales la primera vez que se
+// atiende, para que g.Use() aplique también a las rutas registradas antes
+// sin volver a componerlo en cada solicitud.
+func (g *Gecko) conGlobales(handler HandlerFunc) func() HandlerFunc {
+	var (
+		once     sync.Once
+		completo HandlerFunc
+	)
+	return func() HandlerFunc {
+		once.Do(func() {
+			g.middlewaresMu.Lock()
+			defer g.middlewaresMu.Unlock()
+			g.middlewaresFijos = true
+			completo = encadenarMiddlewares(handler, g.middlewares)
+		})
+		return completo
+	}
+}
+
+// Ejecuta el handler ya envuelto en los middlewares y convierte cualquier
+// panic en un gko.ErrInesperado con el stack trace para que se responda
+// y registre en el log como cualquier otro error.
+func (g *Gecko) ejecutarHandler(c *Context, handler HandlerFunc) (err error) {
+	defer func() {
+		if c.sse != nil {
+			c.sse.cerrar() // Detener keep-alive antes de soltar la respuesta.
+		}
+		rec := recover()
+		if rec == nil {
+			return
 		}
-		err := gko.ErrNoEncontrado
+		if rec == http.ErrAbortHandler {
+			panic(rec) // Usado por net/http para abortar la respuesta a propósito.
+		}
+		err = gko.ErrInesperado.Msg("Hubo un error inesperado en el servidor").
+			Strf("panic: %v\n%s", rec, debug.Stack())
+	}()
+	return handler(c)
+}
+
+// Handler para solicitudes que no coinciden con ninguna ruta registrada,
+// en lugar de los que tiene *http.ServeMux, para responder con el error
+// handler de gecko. Ver g.responderSinRuta.
+func (g *Gecko) registrarNotFoundHandler() {
+	g.sinRuta = g.conGlobales(g.manejarSinRuta)
+	g.mux.HandleFunc("/", g.responderSinRuta)
+}
+
+// Ejecuta g.manejarSinRuta con los middlewares globales, para que
+// también pasen por ellos las respuestas automáticas.
+func (g *Gecko) responderSinRuta(w http.ResponseWriter, r *http.Request) {
+	c := g.nuevoContext(w, r, r.Method+" /{...}")
+	err := g.ejecutarHandler(c, g.sinRuta())
+	g.terminarSolicitud(c, err)
+}
+
+// Responde el error si lo hay, termina de enviar lo comprimido
+// y registra la solicitud en el log.
+func (g *Gecko) terminarSolicitud(c *Context, err error) {
+	if err != nil {
 		g.responderErrorHTTP(c, err)
-		if g.HTTPLogger != nil {
-			g.logHTTP(c, err)
+	}
+	if err := c.response.cerrarCompresor(); err != nil {
+		gko.Err(err).Op("gecko.cerrarCompresor").Log()
+	}
+	if g.HTTPLogger != nil {
+		g.logHTTP(c, err)
+	}
+}
+
+// Responde a una solicitud para la que no hay handler con su método:
+//
+//   - Preflight CORS: según la política de la ruta. Ver g.CORS().
+//   - OPTIONS: responde 204 con el header Allow si la ruta existe.
+//   - Otro método: 405 con el header Allow si la ruta existe con otro método.
+//   - Si no: 404.
+//
+// Las respuestas que no son preflight llevan los headers CORS de la
+// política que aplique para que el cliente pueda leer el error.
+func (g *Gecko) manejarSinRuta(c *Context) error {
+	r := c.request
+	if g.manejarPreflightCORS(c) {
+		return nil // Respondido según la política CORS de la ruta.
+	}
+	permitidos := g.métodosPermitidos(r)
+	if cors := g.políticaCORSSinRuta(r, permitidos); cors != nil {
+		cors.agregarHeaders(c)
+	}
+	switch {
+	case len(permitidos) == 0:
+		return gko.ErrNoEncontrado
+	case r.Method == http.MethodOptions:
+		c.response.Header().Set(HeaderAllow, strings.Join(permitidos, ", "))
+		return c.NoContent(http.StatusNoContent)
+	default:
+		c.response.Header().Set(HeaderAllow, strings.Join(permitidos, ", "))
+		return gko.ErrNoPermitido.Msgf("Método %s no permitido", r.Method)
+	}
+}
+
+// Métodos con los que la ruta de la solicitud sí está registrada,
+// preguntando al mux qué patrón usaría con cada uno. Incluye HEAD
+// si hay GET, y OPTIONS si hay alguno.
+func (g *Gecko) métodosPermitidos(r *http.Request) []string {
+	permitidos := []string{}
+	probar := func(método string) {
+		if slices.Contains(permitidos, método) {
+			return
 		}
-	})
+		prueba := r.Clone(r.Context())
+		prueba.Method = método
+		// Solo cuentan las rutas registradas, no los handlers automáticos.
+		_, patrón := g.mux.Handler(prueba)
+		if _, ok := g.rutasPorPatrón[patrón]; ok {
+			permitidos = append(permitidos, método)
+		}
+	}
+	for _, rt := range g.rutas {
+		probar(rt.Método)
+	}
+	if len(permitidos) == 0 {
+		return permitidos
+	}
+	if slices.Contains(permitidos, http.MethodGet) && !slices.Contains(permitidos, http.MethodHead) {
+		permitidos = append(permitidos, http.MethodHead)
+	}
+	if !slices.Contains(permitidos, http.MethodOptions) {
+		permitidos = append(permitidos, http.MethodOptions)
+	}
+	return permitidos
 }
 
 // Necesario para validar patrón de ruta con método y las reglas de gecko.
@@ -96,30 +233,30 @@ func toMuxPattern(método string, ruta string) string {
 // ================================================================ //
 // ========== Registrar handlers con métodos ====================== //
 
-func (g *Gecko) GET(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodGet, path, handler)
+func (g *Gecko) GET(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodGet, path, handler, mw...)
 }
-func (g *Gecko) POST(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodPost, path, handler)
+func (g *Gecko) POST(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodPost, path, handler, mw...)
 }
-func (g *Gecko) PUT(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodPut, path, handler)
+func (g *Gecko) PUT(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodPut, path, handler, mw...)
 }
-func (g *Gecko) PATCH(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodPatch, path, handler)
+func (g *Gecko) PATCH(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodPatch, path, handler, mw...)
 }
-func (g *Gecko) DELETE(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodDelete, path, handler)
+func (g *Gecko) DELETE(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodDelete, path, handler, mw...)
 }
 
-func (g *Gecko) POS(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodPost, path, handler)
+func (g *Gecko) POS(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodPost, path, handler, mw...)
 }
-func (g *Gecko) PCH(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodPatch, path, handler)
+func (g *Gecko) PCH(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodPatch, path, handler, mw...)
 }
-func (g *Gecko) DEL(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodDelete, path, handler)
+func (g *Gecko) DEL(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodDelete, path, handler, mw...)
 }
 
 // Registra un handler que redirige con StatusSeeOther (303) a la URL dada.
@@ -129,13 +266,18 @@ func (g *Gecko) Redir(path string, redirURL string) {
 	})
 }
 
-/*
-func (g *Gecko) OPTIONS(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodOptions, path, handler)
+// Las rutas GET también responden HEAD sin body y todas las
+// rutas responden OPTIONS con el header Allow automáticamente.
+// Solo es necesario registrarlas para cambiar ese comportamiento.
+
+func (g *Gecko) OPTIONS(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodOptions, path, handler, mw...)
 }
-func (g *Gecko) HEAD(path string, handler HandlerFunc) {
-	g.registrarRuta(http.MethodHead, path, handler)
+func (g *Gecko) HEAD(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return g.registrarRuta(http.MethodHead, path, handler, mw...)
 }
+
+/*
 func (g *Gecko) CONNECT(path string, handler HandlerFunc) {
 	g.registrarRuta(http.MethodConnect, path, handler)
 }
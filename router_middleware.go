@@ -0,0 +1,161 @@
+package gecko
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== MIDDLEWARE ========================================== //
+
+// MiddlewareFunc envuelve un HandlerFunc para ejecutar código antes y
+// después de él. Para detener la cadena basta con retornar un error sin
+// llamar a next, y el error se enviará al cliente con el ErrHandler.
+//
+//	func SoloAdmin(next gecko.HandlerFunc) gecko.HandlerFunc {
+//		return func(c *gecko.Context) error {
+//			if c.Sesion == nil {
+//				return gko.ErrNoAutorizado.Msg("Inicie sesión")
+//			}
+//			return next(c)
+//		}
+//	}
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
+
+// Encadena los middlewares para que se ejecuten en el orden dado:
+// el primero es el más externo y el último el más cercano al handler.
+func encadenarMiddlewares(handler HandlerFunc, mws []MiddlewareFunc) HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// Agrega middlewares globales que envuelven a todas las rutas registradas,
+// incluso las que se registraron antes de llamar a Use, y a las respuestas
+// automáticas 404, 405 y OPTIONS.
+//
+// Se ejecutan por fuera de los middlewares de grupo y de ruta. Se deben
+// agregar antes de atender la primera solicitud, pues en ella se componen.
+func (g *Gecko) Use(mw ...MiddlewareFunc) {
+	g.middlewaresMu.Lock()
+	defer g.middlewaresMu.Unlock()
+	if g.middlewaresFijos {
+		gko.FatalExitf("gecko.Use: middlewares globales agregados después de atender solicitudes")
+	}
+	g.middlewares = append(g.middlewares, mw...)
+}
+
+// ================================================================ //
+// ========== GRUPOS DE RUTAS ===================================== //
+
+// Grupo de rutas que comparten un prefijo y middlewares.
+//
+//	admin := g.Group("/admin", SoloAdmin)
+//	admin.GET("/usuarios", listarUsuarios) // GET /admin/usuarios
+type Grupo struct {
+	gecko       *Gecko
+	prefijo     string
+	middlewares []MiddlewareFunc
+	cors        *PoliticaCORS // Ver gr.CORS().
+
+	limiteBody    *int64 // Ver gr.LimitarBody().
+	limiteArchivo *int64 // Ver gr.LimitarArchivo().
+
+	timeout time.Duration // Ver gr.Timeout().
+}
+
+// Crea un grupo de rutas con el prefijo dado cuyos handlers serán
+// envueltos por los middlewares dados.
+func (g *Gecko) Group(prefijo string, mw ...MiddlewareFunc) *Grupo {
+	return &Grupo{
+		gecko:       g,
+		prefijo:     toPrefijoGrupo(prefijo),
+		middlewares: append([]MiddlewareFunc{}, mw...),
+	}
+}
+
+// Crea un subgrupo que hereda el prefijo, los middlewares, la política
+// CORS, los límites del body y el timeout del grupo.
+func (gr *Grupo) Group(prefijo string, mw ...MiddlewareFunc) *Grupo {
+	mws := make([]MiddlewareFunc, 0, len(gr.middlewares)+len(mw))
+	mws = append(mws, gr.middlewares...)
+	mws = append(mws, mw...)
+	return &Grupo{
+		gecko:       gr.gecko,
+		prefijo:     gr.prefijo + toPrefijoGrupo(prefijo),
+		middlewares: mws,
+		cors:        gr.cors,
+
+		limiteBody:    gr.limiteBody,
+		limiteArchivo: gr.limiteArchivo,
+
+		timeout: gr.timeout,
+	}
+}
+
+// Agrega middlewares al grupo.
+//
+// Solo aplican para las rutas registradas después de llamar a Use.
+func (gr *Grupo) Use(mw ...MiddlewareFunc) {
+	gr.middlewares = append(gr.middlewares, mw...)
+}
+
+// Registrar ruta con el prefijo del grupo y sus middlewares
+// antes de los propios de la ruta.
+func (gr *Grupo) registrarRuta(método string, ruta string, handler HandlerFunc, mw []MiddlewareFunc) *Ruta {
+	mws := make([]MiddlewareFunc, 0, len(gr.middlewares)+len(mw))
+	mws = append(mws, gr.middlewares...)
+	mws = append(mws, mw...)
+	rt := gr.gecko.registrarRuta(método, gr.prefijo+ruta, handler, mws...)
+	rt.cors = gr.cors
+	rt.limiteBody = gr.limiteBody
+	rt.limiteArchivo = gr.limiteArchivo
+	rt.timeout = gr.timeout
+	return rt
+}
+
+// El prefijo debe comenzar con slash y no terminar en slash
+// para poder concatenarse con las rutas del grupo.
+func toPrefijoGrupo(prefijo string) string {
+	if strings.Contains(prefijo, " ") {
+		gko.FatalExitf("gecko.Router: prefijo de grupo no puede contener espacios en blanco: '%s'", prefijo)
+	}
+	prefijo = strings.TrimSuffix(prefijo, "/")
+	if prefijo != "" && prefijo[0] != '/' {
+		gko.FatalExitf("gecko.Router: prefijo de grupo debe comenzar con slash: '%s'", prefijo)
+	}
+	return prefijo
+}
+
+// ================================================================ //
+// ========== Registrar handlers con métodos ====================== //
+
+func (gr *Grupo) GET(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodGet, path, handler, mw)
+}
+func (gr *Grupo) POST(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodPost, path, handler, mw)
+}
+func (gr *Grupo) PUT(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodPut, path, handler, mw)
+}
+func (gr *Grupo) PATCH(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodPatch, path, handler, mw)
+}
+func (gr *Grupo) DELETE(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodDelete, path, handler, mw)
+}
+
+func (gr *Grupo) POS(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodPost, path, handler, mw)
+}
+func (gr *Grupo) PCH(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodPatch, path, handler, mw)
+}
+func (gr *Grupo) DEL(path string, handler HandlerFunc, mw ...MiddlewareFunc) *Ruta {
+	return gr.registrarRuta(http.MethodDelete, path, handler, mw)
+}
@@ -0,0 +1,58 @@
+package gecko
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// Middleware que anota su nombre antes y después del handler.
+func mwOrden(orden *[]string, nombre string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			*orden = append(*orden, nombre)
+			err := next(c)
+			*orden = append(*orden, "/"+nombre)
+			return err
+		}
+	}
+}
+
+func TestOrdenMiddlewares(t *testing.T) {
+	orden := []string{}
+	g := New()
+	// Registrada antes de Use, también la envuelve el global.
+	g.GET("/antes", func(c *Context) error {
+		orden = append(orden, "handler")
+		return c.StringOk("ok")
+	})
+	g.Use(mwOrden(&orden, "global1"), mwOrden(&orden, "global2"))
+	gr := g.Group("/admin", mwOrden(&orden, "grupo"))
+	sub := gr.Group("/sub", mwOrden(&orden, "subgrupo"))
+	sub.GET("/x", func(c *Context) error {
+		orden = append(orden, "handler")
+		return c.StringOk("ok")
+	}, mwOrden(&orden, "ruta"))
+
+	casos := []struct {
+		ruta   string
+		status int
+		orden  []string
+	}{
+		{"/admin/sub/x", http.StatusOK, []string{"global1", "global2", "grupo", "subgrupo", "ruta", "handler", "/ruta", "/subgrupo", "/grupo", "/global2", "/global1"}},
+		{"/antes", http.StatusOK, []string{"global1", "global2", "handler", "/global2", "/global1"}},
+		{"/no/existe", http.StatusNotFound, []string{"global1", "global2", "/global2", "/global1"}},
+	}
+	for _, caso := range casos {
+		orden = orden[:0]
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, caso.ruta, nil))
+		if rec.Code != caso.status {
+			t.Errorf("%s: status %d, se esperaba %d", caso.ruta, rec.Code, caso.status)
+		}
+		if !slices.Equal(orden, caso.orden) {
+			t.Errorf("%s: orden %v\nse esperaba %v", caso.ruta, orden, caso.orden)
+		}
+	}
+}
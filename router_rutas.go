@@ -0,0 +1,154 @@
+package gecko
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== REGISTRO DE RUTAS =================================== //
+
+// Ruta registrada en el router. Se obtiene al registrar un handler
+// para darle un nombre y construir su URL con g.URL().
+//
+//	g.GET("/usuarios/{id}/editar", s.getEditarUsuario).Nombrar("usuario.editar")
+//	g.URL("usuario.editar", 42) // "/usuarios/42/editar"
+type Ruta struct {
+	Método  string      // Ej: "GET".
+	Patrón  string      // Ej: "/usuarios/{id}/editar".
+	Nombre  string      // Opcional. Ver Nombrar().
+	Handler HandlerFunc // Sin middlewares.
+
+	gecko *Gecko
+	cors  *PoliticaCORS // Ver rt.CORS().
+
+	limiteBody    *int64 // Ver rt.LimitarBody().
+	limiteArchivo *int64 // Ver rt.LimitarArchivo().
+
+	timeout time.Duration // Ver rt.Timeout().
+}
+
+// Agrega la ruta al registro para Routes() y URL().
+func (g *Gecko) agregarRuta(método, patrónMux string, handler HandlerFunc) *Ruta {
+	rt := &Ruta{
+		Método:  método,
+		Patrón:  strings.TrimPrefix(patrónMux, método+" "),
+		Handler: handler,
+		gecko:   g,
+	}
+	g.rutas = append(g.rutas, rt)
+	if g.rutasPorPatrón == nil {
+		g.rutasPorPatrón = map[string]*Ruta{}
+	}
+	g.rutasPorPatrón[patrónMux] = rt
+	return rt
+}
+
+// Asigna un nombre único a la ruta para construir su URL con g.URL().
+// Termina el programa si el nombre ya está en uso por otra ruta.
+func (rt *Ruta) Nombrar(nombre string) *Ruta {
+	if nombre == "" {
+		gko.FatalExitf("gecko.Router: nombre vacío para ruta '%s %s'", rt.Método, rt.Patrón)
+	}
+	g := rt.gecko
+	if g.rutasPorNombre == nil {
+		g.rutasPorNombre = map[string]*Ruta{}
+	}
+	if otra, existe := g.rutasPorNombre[nombre]; existe && otra != rt {
+		gko.FatalExitf("gecko.Router: nombre '%s' ya usado por '%s %s'", nombre, otra.Método, otra.Patrón)
+	}
+	rt.Nombre = nombre
+	g.rutasPorNombre[nombre] = rt
+	return rt
+}
+
+// Rutas registradas en el orden en que se registraron.
+func (g *Gecko) Routes() []Ruta {
+	rutas := make([]Ruta, len(g.rutas))
+	for i, rt := range g.rutas {
+		rutas[i] = *rt
+	}
+	return rutas
+}
+
+// Construye la URL de la ruta con el nombre dado llenando sus
+// parámetros en orden con los valores dados.
+//
+//	g.GET("/p/{proyecto}/t/{tarea}", h).Nombrar("tarea")
+//	g.URL("tarea", "gecko", 7) // "/p/gecko/t/7"
+//
+// Retorna error si la ruta no existe o no coincide el número de parámetros.
+// Un parámetro {resto...} acepta slashes en su valor. Para detectar estos
+// errores al iniciar usar MustURL o ValidarURLs del paquete plantillas.
+func (g *Gecko) URL(nombre string, params ...any) (string, error) {
+	rt, ok := g.rutasPorNombre[nombre]
+	if !ok {
+		return "", gko.ErrNoEncontrado.Strf("gecko.URL: ruta '%s' no registrada", nombre)
+	}
+	return rt.URL(params...)
+}
+
+// Como g.URL() pero termina el programa si hay error.
+// Usar al iniciar la aplicación, no en handlers.
+func (g *Gecko) MustURL(nombre string, params ...any) string {
+	u, err := g.URL(nombre, params...)
+	if err != nil {
+		gko.Err(err).FatalExit()
+	}
+	return u
+}
+
+// Construye la URL de la ruta llenando sus parámetros en orden.
+func (rt *Ruta) URL(params ...any) (string, error) {
+	op := gko.Op("gecko.URL").Ctx("ruta", rt.Nombre)
+	var b strings.Builder
+	resto := strings.TrimSuffix(rt.Patrón, "/{$}")
+	usados := 0
+	for {
+		inicio := strings.IndexByte(resto, '{')
+		if inicio < 0 {
+			b.WriteString(resto)
+			break
+		}
+		fin := strings.IndexByte(resto[inicio:], '}')
+		if fin < 0 {
+			return "", op.E(gko.ErrInesperado).Strf("patrón inválido '%s'", rt.Patrón)
+		}
+		fin += inicio
+		b.WriteString(resto[:inicio])
+		nombreParam := resto[inicio+1 : fin]
+		resto = resto[fin+1:]
+
+		if nombreParam == "$" {
+			continue
+		}
+		if usados >= len(params) {
+			return "", op.E(gko.ErrDatoIndef).Strf("falta parámetro {%s} de '%s'", nombreParam, rt.Patrón)
+		}
+		valor := fmt.Sprint(params[usados])
+		usados++
+		if valor == "" {
+			return "", op.E(gko.ErrDatoIndef).Strf("parámetro {%s} vacío", nombreParam)
+		}
+		if strings.HasSuffix(nombreParam, "...") {
+			segmentos := strings.Split(valor, "/")
+			for i, seg := range segmentos {
+				segmentos[i] = url.PathEscape(seg)
+			}
+			b.WriteString(strings.Join(segmentos, "/"))
+		} else {
+			b.WriteString(url.PathEscape(valor))
+		}
+	}
+	if usados < len(params) {
+		return "", op.E(gko.ErrDatoInvalido).Strf("%d parámetros de más para '%s'", len(params)-usados, rt.Patrón)
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,87 @@
+package gecko
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+func nuevoGeckoRutas() *Gecko {
+	h := func(c *Context) error { return nil }
+	g := New()
+	g.GET("/", h).Nombrar("inicio")
+	g.GET("/usuarios/{id}/editar", h).Nombrar("usuario.editar")
+	g.GET("/p/{proyecto}/t/{tarea}", h).Nombrar("tarea")
+	g.GET("/archivos/{ruta...}", h).Nombrar("archivo")
+	g.GET("/docs/{$}", h).Nombrar("docs")
+	g.Group("/admin").POST("/usuarios/{id}", h).Nombrar("admin.usuario")
+	return g
+}
+
+func TestURL(t *testing.T) {
+	g := nuevoGeckoRutas()
+	casos := []struct {
+		nombre string
+		params []any
+		url    string
+		err    gko.ErrorKey // Vacío si no hay error.
+	}{
+		{"inicio", nil, "/", ""},
+		{"usuario.editar", []any{42}, "/usuarios/42/editar", ""},
+		{"usuario.editar", []any{"a b/c?"}, "/usuarios/a%20b%2Fc%3F/editar", ""},
+		{"tarea", []any{"gecko", 7}, "/p/gecko/t/7", ""},
+		{"archivo", []any{"img/logo final.png"}, "/archivos/img/logo%20final.png", ""},
+		{"docs", nil, "/docs", ""},
+		{"admin.usuario", []any{uint(3)}, "/admin/usuarios/3", ""},
+		{"tarea", []any{"gecko"}, "", gko.ErrDatoIndef},
+		{"usuario.editar", nil, "", gko.ErrDatoIndef},
+		{"usuario.editar", []any{""}, "", gko.ErrDatoIndef},
+		{"usuario.editar", []any{1, 2}, "", gko.ErrDatoInvalido},
+		{"inicio", []any{1}, "", gko.ErrDatoInvalido},
+		{"no.existe", nil, "", gko.ErrNoEncontrado},
+	}
+	for _, caso := range casos {
+		url, err := g.URL(caso.nombre, caso.params...)
+		if caso.err == "" {
+			if err != nil || url != caso.url {
+				t.Errorf("URL(%q, %v) = %q, %v; se esperaba %q", caso.nombre, caso.params, url, err, caso.url)
+			}
+			continue
+		}
+		if !gko.Is(err, caso.err) {
+			t.Errorf("URL(%q, %v): error %v, se esperaba %s", caso.nombre, caso.params, err, caso.err)
+		}
+	}
+	if url := g.MustURL("tarea", "gecko", 7); url != "/p/gecko/t/7" {
+		t.Errorf("MustURL: %q", url)
+	}
+
+	rutas := g.Routes()
+	if len(rutas) != 6 || rutas[5].Método != "POST" || rutas[5].Patrón != "/admin/usuarios/{id}" || rutas[5].Nombre != "admin.usuario" {
+		t.Errorf("Routes: %+v", rutas)
+	}
+}
+
+// MustURL termina el programa si la ruta no existe o faltan
+// parámetros, por lo que se prueba en otro proceso.
+func TestMustURLTermina(t *testing.T) {
+	if caso := os.Getenv("GECKO_PRUEBA_MUSTURL"); caso != "" {
+		g := nuevoGeckoRutas()
+		if caso == "desconocida" {
+			g.MustURL("no.existe")
+		} else {
+			g.MustURL("tarea", "gecko")
+		}
+		os.Exit(0)
+	}
+	for _, caso := range []string{"desconocida", "sin parámetro"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestMustURLTermina$")
+		cmd.Env = append(os.Environ(), "GECKO_PRUEBA_MUSTURL="+caso)
+		err := cmd.Run()
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			t.Errorf("%s: se esperaba salida con código 1, no %v", caso, err)
+		}
+	}
+}
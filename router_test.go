@@ -0,0 +1,106 @@
+package gecko
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicEnHandler(t *testing.T) {
+	logger := &loggerPrueba{}
+	g := New()
+	g.HTTPLogger = logger
+	g.GET("/panic", func(c *Context) error {
+		panic("algo salió mal")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d", rec.Code)
+	}
+	var res map[string]ErrorJSON
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res["error"].Clave != "inesperado" || strings.Contains(rec.Body.String(), "algo salió mal") {
+		t.Errorf("respuesta %s", rec.Body.String())
+	}
+
+	// El panic y su stack trace quedan en el log, no en la respuesta.
+	g.logsPendientes.Wait()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.entries) != 1 {
+		t.Fatalf("%d entradas de log", len(logger.entries))
+	}
+	if e := logger.entries[0].Error; !strings.Contains(e, "panic: algo salió mal") || !strings.Contains(e, "goroutine") {
+		t.Errorf("log sin panic ni stack: %q", e)
+	}
+}
+
+func TestPanicErrAbortHandler(t *testing.T) {
+	g := New()
+	g.GET("/abortar", func(c *Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("se esperaba panic con http.ErrAbortHandler, se obtuvo %v", rec)
+		}
+	}()
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abortar", nil))
+	t.Error("ServeHTTP no debió regresar")
+}
+
+func TestRespuestasAutomaticas(t *testing.T) {
+	ok := func(c *Context) error { return c.StringOk("contenido") }
+	g := NewSinRoot404()
+	g.GET("/items", ok)
+	g.POST("/items", ok)
+	g.PUT("/items/{id}", ok)
+	g.GET("/docs/{ruta...}", ok)
+
+	casos := []struct {
+		método string
+		ruta   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/items", http.StatusOK, ""},
+		{http.MethodGet, "/items/", http.StatusOK, ""},
+		{http.MethodOptions, "/items", http.StatusNoContent, "GET, POST, HEAD, OPTIONS"},
+		{http.MethodDelete, "/items", http.StatusMethodNotAllowed, "GET, POST, HEAD, OPTIONS"},
+		{http.MethodOptions, "/items/3", http.StatusNoContent, "PUT, OPTIONS"},
+		{http.MethodGet, "/items/3", http.StatusMethodNotAllowed, "PUT, OPTIONS"},
+		{http.MethodPost, "/docs/a/b", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS"},
+		{http.MethodGet, "/nada", http.StatusNotFound, ""},
+		{http.MethodOptions, "/nada", http.StatusNotFound, ""},
+	}
+	for _, caso := range casos {
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, httptest.NewRequest(caso.método, caso.ruta, nil))
+		if rec.Code != caso.status || rec.Header().Get(HeaderAllow) != caso.allow {
+			t.Errorf("%s %s: status %d, Allow %q; se esperaba %d, %q",
+				caso.método, caso.ruta, rec.Code, rec.Header().Get(HeaderAllow), caso.status, caso.allow)
+		}
+	}
+
+	// HEAD usa el handler GET con sus headers pero sin body.
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+	res, err := http.Head(srv.URL + "/docs/guia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || len(body) != 0 || res.ContentLength != int64(len("contenido")) {
+		t.Errorf("HEAD: status %d, Content-Length %d, body %q", res.StatusCode, res.ContentLength, body)
+	}
+}
@@ -0,0 +1,166 @@
+package gecko
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== TIMEOUT POR RUTA ==================================== //
+
+// Tiempo máximo para responder esta ruta. Al vencer se cancela el
+// contexto de la solicitud y el cliente recibe gko.ErrHandlerTimeout
+// (503) si el handler aún no había comenzado a responder. El error
+// también contiene gko.ErrTimeout.
+//
+//	g.GET("/reportes/anual", s.getReporteAnual).Timeout(30 * time.Second)
+//
+// Lo que escriba el handler después de vencer se descarta, por lo que
+// no sirve para rutas con SSE o WebSocket. En estas rutas Hijack siempre
+// falla con gko.ErrNoSoportado, así que c.WebSocket no puede abrir la
+// conexión aunque no haya vencido el tiempo.
+func (rt *Ruta) Timeout(límite time.Duration) *Ruta {
+	rt.timeout = límite
+	return rt
+}
+
+// Tiempo máximo para responder las rutas del grupo registradas después.
+func (gr *Grupo) Timeout(límite time.Duration) {
+	gr.timeout = límite
+}
+
+// Ejecuta el handler en otra goroutine con su propio Context y Response
+// para que al vencer el tiempo se pueda responder el error sin competir
+// con lo que el handler siga haciendo.
+func (g *Gecko) ejecutarConTimeout(c *Context, handler HandlerFunc, límite time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.request.Context(), límite)
+	defer cancel()
+	c.request = c.request.WithContext(ctx)
+
+	w := &escritorTimeout{w: c.response.Writer, h: c.response.Header().Clone()}
+	cH := *c
+	cH.response = NewResponse(w, g)
+	cH.response.Before(cH.negociarCompresión)
+
+	fin := make(chan error, 1)
+	pánico := make(chan any, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				pánico <- rec // Solo http.ErrAbortHandler llega aquí.
+			}
+		}()
+		err := g.ejecutarHandler(&cH, handler)
+		w.mu.Lock()
+		w.terminado = true
+		vencido := w.vencido
+		w.mu.Unlock()
+		if vencido {
+			cH.response.cerrarCompresor()
+			if err != nil {
+				gko.Err(err).Op(c.path).Op("después de timeout").Log()
+			}
+		}
+		fin <- err
+	}()
+
+	select {
+	case err := <-fin:
+		*c = cH
+		return err
+	case rec := <-pánico:
+		panic(rec)
+	case <-ctx.Done():
+	}
+
+	w.mu.Lock()
+	if w.terminado { // Terminó justo al vencer.
+		w.mu.Unlock()
+		err := <-fin
+		*c = cH
+		return err
+	}
+	w.vencido = true
+	if w.committed {
+		// Ya se envió parte de la respuesta y no se puede cambiar.
+		c.response.Status, c.response.Size, c.response.Committed = w.status, w.size, true
+	}
+	w.mu.Unlock()
+	// No es 408 porque el cliente sí envió la solicitud a tiempo.
+	return gko.ErrTimeout.Msg("La solicitud tardó demasiado").E(gko.ErrHandlerTimeout).
+		Strf("handler sin terminar después de %v", límite)
+}
+
+// ================================================================ //
+
+// ResponseWriter para el handler con timeout. Guarda sus headers aparte
+// y deja de escribir al cliente cuando vence el tiempo.
+type escritorTimeout struct {
+	w http.ResponseWriter
+	h http.Header
+
+	mu        sync.Mutex
+	vencido   bool // Ya se respondió al cliente por timeout.
+	terminado bool // El handler ya regresó.
+	committed bool
+	status    int
+	size      uint64
+}
+
+func (e *escritorTimeout) Header() http.Header {
+	return e.h
+}
+
+func (e *escritorTimeout) WriteHeader(code int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.vencido || e.committed {
+		return
+	}
+	e.escribirHeader(code)
+}
+
+// Copia los headers del handler a la respuesta real. Con mu bloqueado.
+func (e *escritorTimeout) escribirHeader(code int) {
+	real := e.w.Header()
+	for k := range real {
+		delete(real, k)
+	}
+	for k, v := range e.h {
+		real[k] = v
+	}
+	e.w.WriteHeader(code)
+	e.committed, e.status = true, code
+}
+
+func (e *escritorTimeout) Write(b []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.vencido {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !e.committed {
+		e.escribirHeader(http.StatusOK)
+	}
+	n, err := e.w.Write(b)
+	e.size += uint64(n)
+	return n, err
+}
+
+func (e *escritorTimeout) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if f, ok := e.w.(http.Flusher); ok && !e.vencido {
+		f.Flush()
+	}
+}
+
+func (e *escritorTimeout) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, gko.ErrNoSoportado.Str("gecko: hijack no soportado en rutas con timeout")
+}
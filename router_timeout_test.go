@@ -0,0 +1,113 @@
+package gecko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+func TestTimeout(t *testing.T) {
+	g := New()
+	g.GET("/rapido", func(c *Context) error {
+		c.Response().Header().Set("X-Handler", "si")
+		return c.StringOk("ok")
+	}).Timeout(time.Second)
+
+	liberar := make(chan struct{})
+	errTarde := make(chan error, 1)
+	errCtx := make(chan error, 1)
+	g.GET("/lento", func(c *Context) error {
+		<-c.Request().Context().Done()
+		errCtx <- c.Request().Context().Err()
+		<-liberar // Escribir cuando ya se respondió el timeout.
+		c.Response().Header().Set("X-Handler", "tarde")
+		_, err := c.Response().Write([]byte("tarde"))
+		errTarde <- err
+		return err
+	}).Timeout(20 * time.Millisecond)
+
+	bloquear := make(chan struct{})
+	defer close(bloquear)
+	grupo := g.Group("/grupo")
+	grupo.Timeout(20 * time.Millisecond)
+	grupo.GET("/lento", func(c *Context) error {
+		<-bloquear
+		return nil
+	})
+
+	g.GET("/panic", func(c *Context) error {
+		panic("algo salió mal")
+	}).Timeout(time.Second)
+
+	// Termina antes de vencer.
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rapido", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" || rec.Header().Get("X-Handler") != "si" {
+		t.Errorf("rápido: status %d body %q headers %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	// Excede el tiempo.
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lento", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("lento: status %d", rec.Code)
+	}
+	if err := <-errCtx; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("lento: contexto %v", err)
+	}
+	body := rec.Body.String()
+	close(liberar)
+	if err := <-errTarde; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("escritura tardía: %v", err)
+	}
+	if rec.Body.String() != body || strings.Contains(body, "tarde") || rec.Header().Get("X-Handler") != "" {
+		t.Errorf("escritura tardía llegó al cliente: %q %v", rec.Body.String(), rec.Header())
+	}
+
+	// Heredado del grupo.
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/grupo/lento", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("grupo: status %d", rec.Code)
+	}
+
+	// Panic dentro de la goroutine del handler.
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("panic: status %d", rec.Code)
+	}
+}
+
+func TestTimeoutAbortHandler(t *testing.T) {
+	g := New()
+	g.GET("/abortar", func(c *Context) error {
+		panic(http.ErrAbortHandler)
+	}).Timeout(time.Second)
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("se esperaba http.ErrAbortHandler en el handler del servidor: %v", rec)
+		}
+	}()
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abortar", nil))
+}
+
+func TestTimeoutSinHijack(t *testing.T) {
+	g := New()
+	errHijack := make(chan error, 1)
+	g.GET("/ws", func(c *Context) error {
+		_, _, err := c.Response().Hijack()
+		errHijack <- err
+		return err
+	}).Timeout(time.Second)
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if err := <-errHijack; !gko.Is(err, gko.ErrNoSoportado) {
+		t.Errorf("Hijack con timeout: %v", err)
+	}
+}
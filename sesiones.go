@@ -0,0 +1,263 @@
+package gecko
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/gkoid"
+)
+
+// ================================================================ //
+// ========== SESIÓN ============================================== //
+
+// Sesión de usuario guardada en el servidor e identificada
+// en el cliente por una cookie con su ID.
+type Sesion struct {
+	ID        string            // Aleatorio e impredecible, nunca exponerlo en logs.
+	UsuarioID string            // Vacío si la sesión es anónima.
+	Datos     map[string]string // Valores adicionales de la aplicación.
+	Creada    time.Time
+	Expira    time.Time
+}
+
+// Reporta si la sesión ya expiró en el momento dado.
+func (s *Sesion) Expirada(t time.Time) bool {
+	return !s.Expira.After(t)
+}
+
+// Persistencia de sesiones para el ServicioSesiones.
+//
+// Ver NuevoSessionStoreMemoria() o el paquete sesionsqlite.
+type SessionStore interface {
+	Get(id string) (*Sesion, error) // Retorna gko.ErrNoEncontrado si no existe.
+	Save(sesion *Sesion) error      // Inserta o actualiza la sesión.
+	Delete(id string) error         // No retorna error si no existe.
+	DeleteExpired(t time.Time) error
+}
+
+// Sesión cargada por el middleware de sesiones para esta solicitud.
+// Retorna nil si no hay sesión.
+func (c *Context) GetSesion() *Sesion {
+	return c.sesion
+}
+
+// ================================================================ //
+// ========== SERVICIO ============================================ //
+
+const (
+	defaultSesionCookie   = "sesion"
+	defaultSesionDuracion = 24 * time.Hour
+	largoSesionID         = 32 // 192 bits con gkoid.New64
+)
+
+// Servicio para iniciar, cargar y terminar sesiones.
+//
+// Su middleware pone en c.SesionID y c.Sesion la sesión de cada
+// solicitud para que la usen los handlers, el render y los logs.
+//
+//	sesiones := gecko.NuevoServicioSesiones(gecko.NuevoSessionStoreMemoria())
+//	sesiones.Cargar = func(s *gecko.Sesion) (any, error) {
+//		return app.GetUsuario(s.UsuarioID)
+//	}
+//	g.Use(sesiones.Middleware)
+type ServicioSesiones struct {
+	Store    SessionStore
+	Cookie   string        // Nombre de la cookie. Default "sesion".
+	Duracion time.Duration // Se extiende con cada solicitud. Default 24h.
+
+	// Convierte la sesión en lo que se pondrá en c.Sesion, por ejemplo el
+	// usuario de la aplicación. Si es nil se pone la *Sesion tal cual.
+	Cargar func(s *Sesion) (any, error)
+}
+
+// Nuevo servicio de sesiones con la configuración default.
+func NuevoServicioSesiones(store SessionStore) *ServicioSesiones {
+	return &ServicioSesiones{
+		Store:    store,
+		Cookie:   defaultSesionCookie,
+		Duracion: defaultSesionDuracion,
+	}
+}
+
+func (s *ServicioSesiones) getCookie() string {
+	if s.Cookie == "" {
+		return defaultSesionCookie
+	}
+	return s.Cookie
+}
+
+func (s *ServicioSesiones) getDuracion() time.Duration {
+	if s.Duracion <= 0 {
+		return defaultSesionDuracion
+	}
+	return s.Duracion
+}
+
+// ================================================================ //
+
+// Middleware que carga la sesión indicada por la cookie y la pone en
+// c.SesionID y c.Sesion antes de ejecutar el handler.
+//
+// Si la sesión no existe o expiró se borra la cookie y se continúa
+// sin sesión. Cuando ya pasó la mitad de su duración se extiende.
+func (s *ServicioSesiones) Middleware(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		cookie, err := c.Cookie(s.getCookie())
+		if err != nil || cookie.Value == "" {
+			return next(c)
+		}
+		sesion, err := s.Store.Get(cookie.Value)
+		if gko.EsErrNotFound(err) {
+			s.borrarCookie(c)
+			return next(c)
+		}
+		if err != nil {
+			return gko.Err(err).Op("gecko.Sesiones.Get")
+		}
+		ahora := time.Now()
+		if sesion.Expirada(ahora) {
+			err = s.Store.Delete(sesion.ID)
+			if err != nil {
+				gko.Err(err).Op("gecko.Sesiones.DeleteExpirada").Log()
+			}
+			s.borrarCookie(c)
+			return next(c)
+		}
+		// Sliding expiration sin escribir en cada solicitud.
+		if sesion.Expira.Sub(ahora) < s.getDuracion()/2 {
+			sesion.Expira = ahora.Add(s.getDuracion())
+			err = s.Store.Save(sesion)
+			if err != nil {
+				return gko.Err(err).Op("gecko.Sesiones.Extender")
+			}
+			s.ponerCookie(c, sesion)
+		}
+		err = s.ponerEnContext(c, sesion)
+		if err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// Inicia una sesión para el usuario, por ejemplo al hacer login.
+//
+// Si ya había una sesión se elimina y se crea otra con nuevo ID pero
+// los mismos datos, para evitar ataques de fijación de sesión. El token
+// CSRF no se conserva para que se emita otro con los nuevos privilegios.
+func (s *ServicioSesiones) Iniciar(c *Context, usuarioID string) (*Sesion, error) {
+	op := gko.Op("gecko.Sesiones.Iniciar")
+	id, err := gkoid.New64(largoSesionID)
+	if err != nil {
+		return nil, op.Err(err)
+	}
+	ahora := time.Now()
+	nueva := &Sesion{
+		ID:        id,
+		UsuarioID: usuarioID,
+		Datos:     map[string]string{},
+		Creada:    ahora,
+		Expira:    ahora.Add(s.getDuracion()),
+	}
+	if anterior := c.sesion; anterior != nil {
+		for k, v := range anterior.Datos {
+			nueva.Datos[k] = v
+		}
+		delete(nueva.Datos, datoSesionCSRF)
+		err = s.Store.Delete(anterior.ID)
+		if err != nil {
+			return nil, op.Err(err).Op("RotarSesion")
+		}
+	}
+	err = s.Store.Save(nueva)
+	if err != nil {
+		return nil, op.Err(err)
+	}
+	s.ponerCookie(c, nueva)
+	err = s.ponerEnContext(c, nueva)
+	if err != nil {
+		return nil, op.Err(err)
+	}
+	return nueva, nil
+}
+
+// Rotar el ID de la sesión actual conservando su usuario y datos,
+// excepto el token CSRF.
+// Útil al cambiar privilegios del usuario.
+func (s *ServicioSesiones) Rotar(c *Context) (*Sesion, error) {
+	if c.sesion == nil {
+		return nil, gko.ErrNoEncontrado.Op("gecko.Sesiones.Rotar").Str("no hay sesión que rotar")
+	}
+	return s.Iniciar(c, c.sesion.UsuarioID)
+}
+
+// Guarda los cambios hechos a los datos de la sesión actual.
+func (s *ServicioSesiones) Guardar(c *Context) error {
+	if c.sesion == nil {
+		return gko.ErrNoEncontrado.Op("gecko.Sesiones.Guardar").Str("no hay sesión que guardar")
+	}
+	return s.Store.Save(c.sesion)
+}
+
+// Termina la sesión actual, por ejemplo al hacer logout.
+func (s *ServicioSesiones) Terminar(c *Context) error {
+	s.borrarCookie(c)
+	if c.sesion == nil {
+		return nil
+	}
+	err := s.Store.Delete(c.sesion.ID)
+	if err != nil {
+		return gko.Err(err).Op("gecko.Sesiones.Terminar")
+	}
+	c.sesion = nil
+	c.SesionID = ""
+	c.Sesion = nil
+	return nil
+}
+
+// Elimina del store las sesiones expiradas.
+func (s *ServicioSesiones) PurgarExpiradas() error {
+	return s.Store.DeleteExpired(time.Now())
+}
+
+// ================================================================ //
+
+func (s *ServicioSesiones) ponerEnContext(c *Context, sesion *Sesion) error {
+	c.sesion = sesion
+	c.SesionID = sesion.ID
+	if s.Cargar == nil {
+		c.Sesion = sesion
+		return nil
+	}
+	valor, err := s.Cargar(sesion)
+	if err != nil {
+		return gko.Err(err).Op("gecko.Sesiones.Cargar")
+	}
+	c.Sesion = valor
+	return nil
+}
+
+func (s *ServicioSesiones) ponerCookie(c *Context, sesion *Sesion) {
+	c.SetCookie(&http.Cookie{
+		Name:     s.getCookie(),
+		Value:    sesion.ID,
+		Path:     "/",
+		Expires:  sesion.Expira,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (s *ServicioSesiones) borrarCookie(c *Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     s.getCookie(),
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,91 @@
+package gecko
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// Implementación de SessionStore en memoria.
+//
+// Las sesiones se pierden al reiniciar el servidor.
+// Las expiradas se eliminan cada 5 minutos hasta llamar a Close.
+type SessionStoreMemoria struct {
+	sesiones map[string]Sesion
+	mu       sync.RWMutex
+	ticker   *time.Ticker
+	cerrar   chan struct{}
+	once     sync.Once
+}
+
+func NuevoSessionStoreMemoria() *SessionStoreMemoria {
+	s := &SessionStoreMemoria{
+		sesiones: make(map[string]Sesion),
+		ticker:   time.NewTicker(5 * time.Minute),
+		cerrar:   make(chan struct{}),
+	}
+	go func() {
+		for {
+			select {
+			case t := <-s.ticker.C:
+				s.DeleteExpired(t)
+			case <-s.cerrar:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Detiene la limpieza periódica de sesiones expiradas.
+// Las sesiones se siguen pudiendo usar.
+func (s *SessionStoreMemoria) Close() {
+	s.once.Do(func() {
+		s.ticker.Stop()
+		close(s.cerrar)
+	})
+}
+
+// Retorna una copia para que los handlers no compartan la misma sesión.
+func (s *SessionStoreMemoria) Get(id string) (*Sesion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sesion, ok := s.sesiones[id]
+	if !ok {
+		return nil, gko.ErrNoEncontrado.Op("SessionStoreMemoria.Get")
+	}
+	sesion.Datos = maps.Clone(sesion.Datos)
+	return &sesion, nil
+}
+
+func (s *SessionStoreMemoria) Save(sesion *Sesion) error {
+	if sesion == nil || sesion.ID == "" {
+		return gko.ErrDatoIndef.Op("SessionStoreMemoria.Save").Str("sesión sin ID")
+	}
+	guardada := *sesion
+	guardada.Datos = maps.Clone(sesion.Datos)
+	s.mu.Lock()
+	s.sesiones[sesion.ID] = guardada
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *SessionStoreMemoria) Delete(id string) error {
+	s.mu.Lock()
+	delete(s.sesiones, id)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *SessionStoreMemoria) DeleteExpired(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, sesion := range s.sesiones {
+		if sesion.Expirada(t) {
+			delete(s.sesiones, id)
+		}
+	}
+	return nil
+}
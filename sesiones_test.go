@@ -0,0 +1,178 @@
+package gecko
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// Cookie con el nombre dado en la respuesta o nil.
+func cookieRespuesta(rec *httptest.ResponseRecorder, nombre string) *http.Cookie {
+	for _, ck := range rec.Result().Cookies() {
+		if ck.Name == nombre {
+			return ck
+		}
+	}
+	return nil
+}
+
+// Servidor con rutas para iniciar, rotar, consultar y terminar la sesión.
+func servidorSesiones(store SessionStore) *Gecko {
+	sesiones := NuevoServicioSesiones(store)
+	g := New()
+	g.Use(sesiones.Middleware)
+	g.POST("/login", func(c *Context) error {
+		_, err := sesiones.Iniciar(c, c.QueryVal("usuario"))
+		if err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	g.POST("/rotar", func(c *Context) error {
+		_, err := sesiones.Rotar(c)
+		if err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	g.GET("/yo", func(c *Context) error {
+		if c.GetSesion() == nil {
+			return c.StringOk("anónimo")
+		}
+		return c.StringOk(c.GetSesion().UsuarioID)
+	})
+	g.POST("/logout", func(c *Context) error {
+		err := sesiones.Terminar(c)
+		if err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	return g
+}
+
+// Crear, leer, rotar y terminar una sesión con el store dado.
+func probarFlujoSesiones(t *testing.T, store SessionStore) {
+	t.Helper()
+	g := servidorSesiones(store)
+
+	rec := solicitudCSRF(g, httptest.NewRequest(http.MethodPost, "/login?usuario=ana", nil))
+	cookie := cookieRespuesta(rec, defaultSesionCookie)
+	if rec.Code != http.StatusOK || cookie == nil || len(cookie.Value) != largoSesionID {
+		t.Fatalf("login: status %d con cookie %v", rec.Code, cookie)
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie sin HttpOnly o SameSite: %v", cookie)
+	}
+	if rec := solicitudCSRF(g, httptest.NewRequest(http.MethodGet, "/yo", nil), cookie); rec.Body.String() != "ana" {
+		t.Errorf("leer: %q", rec.Body.String())
+	}
+
+	// Datos de la aplicación y token CSRF antes de rotar.
+	sesion, err := store.Get(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sesion.Datos["tema"] = "oscuro"
+	sesion.Datos[datoSesionCSRF] = "token-anterior"
+	if err := store.Save(sesion); err != nil {
+		t.Fatal(err)
+	}
+	rec = solicitudCSRF(g, httptest.NewRequest(http.MethodPost, "/rotar", nil), cookie)
+	rotada := cookieRespuesta(rec, defaultSesionCookie)
+	if rec.Code != http.StatusOK || rotada == nil || rotada.Value == cookie.Value {
+		t.Fatalf("rotar: status %d con cookie %v", rec.Code, rotada)
+	}
+	if _, err := store.Get(cookie.Value); !gko.EsErrNotFound(err) {
+		t.Errorf("sesión anterior sigue en el store: %v", err)
+	}
+	nueva, err := store.Get(rotada.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nueva.UsuarioID != "ana" || nueva.Datos["tema"] != "oscuro" {
+		t.Errorf("sesión rotada sin usuario o datos: %+v", nueva)
+	}
+	if _, ok := nueva.Datos[datoSesionCSRF]; ok {
+		t.Error("la sesión rotada conservó el token CSRF")
+	}
+	if rec := solicitudCSRF(g, httptest.NewRequest(http.MethodGet, "/yo", nil), cookie); rec.Body.String() != "anónimo" {
+		t.Errorf("cookie anterior sigue válida: %q", rec.Body.String())
+	}
+
+	rec = solicitudCSRF(g, httptest.NewRequest(http.MethodPost, "/logout", nil), rotada)
+	if ck := cookieRespuesta(rec, defaultSesionCookie); ck == nil || ck.MaxAge >= 0 {
+		t.Errorf("logout no borró la cookie: %v", ck)
+	}
+	if _, err := store.Get(rotada.Value); !gko.EsErrNotFound(err) {
+		t.Errorf("sesión terminada sigue en el store: %v", err)
+	}
+}
+
+// Sesión expirada en el store: se ignora, se borra y se quita la cookie.
+func probarSesionExpirada(t *testing.T, store SessionStore) {
+	t.Helper()
+	ahora := time.Now()
+	expirada := &Sesion{ID: "sesion-expirada", UsuarioID: "ana", Datos: map[string]string{},
+		Creada: ahora.Add(-2 * time.Hour), Expira: ahora.Add(-time.Hour)}
+	vigente := &Sesion{ID: "sesion-vigente", UsuarioID: "beto", Datos: map[string]string{},
+		Creada: ahora, Expira: ahora.Add(time.Hour)}
+	for _, s := range []*Sesion{expirada, vigente} {
+		if err := store.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g := servidorSesiones(store)
+	rec := solicitudCSRF(g, httptest.NewRequest(http.MethodGet, "/yo", nil),
+		&http.Cookie{Name: defaultSesionCookie, Value: expirada.ID})
+	if rec.Body.String() != "anónimo" {
+		t.Errorf("sesión expirada aceptada: %q", rec.Body.String())
+	}
+	if ck := cookieRespuesta(rec, defaultSesionCookie); ck == nil || ck.MaxAge >= 0 {
+		t.Errorf("no se borró la cookie de la sesión expirada: %v", ck)
+	}
+	if _, err := store.Get(expirada.ID); !gko.EsErrNotFound(err) {
+		t.Errorf("sesión expirada sigue en el store: %v", err)
+	}
+
+	if err := store.Save(expirada); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteExpired(ahora); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(expirada.ID); !gko.EsErrNotFound(err) {
+		t.Errorf("DeleteExpired no eliminó la sesión expirada: %v", err)
+	}
+	if _, err := store.Get(vigente.ID); err != nil {
+		t.Errorf("DeleteExpired eliminó la sesión vigente: %v", err)
+	}
+}
+
+func TestSesionesMemoria(t *testing.T) {
+	store := NuevoSessionStoreMemoria()
+	defer store.Close()
+	probarFlujoSesiones(t, store)
+	probarSesionExpirada(t, store)
+}
+
+func TestSessionStoreMemoriaCopia(t *testing.T) {
+	store := NuevoSessionStoreMemoria()
+	store.Close()
+	store.Close() // Se puede llamar varias veces.
+	err := store.Save(&Sesion{ID: "x", Datos: map[string]string{"a": "1"}, Expira: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sesion, err := store.Get("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sesion.Datos["a"] = "2"
+	if otra, _ := store.Get("x"); otra.Datos["a"] != "1" {
+		t.Error("modificar la sesión obtenida cambió la guardada sin Save")
+	}
+}
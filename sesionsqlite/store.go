@@ -0,0 +1,124 @@
+package sesionsqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pargomx/gecko"
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/sqlitedb"
+)
+
+// Siempre en UTC y de ancho fijo para poder comparar como texto en sqlite.
+const formatoTimestamp = "2006-01-02 15:04:05.000000Z07:00"
+
+const createTableSesiones = `
+CREATE TABLE sesiones (
+  sesion_id TEXT NOT NULL,
+  usuario_id TEXT NOT NULL DEFAULT '',
+  datos TEXT NOT NULL DEFAULT '{}',
+  creada TEXT NOT NULL,
+  expira TEXT NOT NULL,
+  PRIMARY KEY (sesion_id)
+);
+CREATE INDEX index_sesiones_expira ON sesiones (expira);
+`
+
+// Implementación de gecko.SessionStore en sqlite.
+type SessionStore struct {
+	db sqlitedb.Ejecutor
+}
+
+// Prepara el store creando la tabla sesiones si no existe.
+func NuevoSessionStore(db sqlitedb.Ejecutor) (*SessionStore, error) {
+	const op = "sesionsqlite.NuevoSessionStore"
+	const checkTableExists = "SELECT name FROM sqlite_master WHERE type='table' AND name='sesiones';"
+	var tableName string
+	err := db.QueryRow(checkTableExists).Scan(&tableName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, gko.Err(err).Op(op)
+	}
+	if tableName == "" {
+		_, err := db.Exec(createTableSesiones)
+		if err != nil {
+			return nil, gko.Err(err).Op(op)
+		}
+		gko.LogInfo("SesionSqlite: nueva tabla preparada")
+	}
+	return &SessionStore{db: db}, nil
+}
+
+// ================================================================ //
+
+func (s *SessionStore) Get(id string) (*gecko.Sesion, error) {
+	const op string = "sesionsqlite.Get"
+	var datos, creada, expira string
+	sesion := &gecko.Sesion{}
+	err := s.db.QueryRow(
+		"SELECT sesion_id, usuario_id, datos, creada, expira FROM sesiones WHERE sesion_id = ?", id,
+	).Scan(&sesion.ID, &sesion.UsuarioID, &datos, &creada, &expira)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, gko.ErrNoEncontrado.Op(op)
+	}
+	if err != nil {
+		return nil, gko.ErrInesperado.Err(err).Op(op)
+	}
+	err = json.Unmarshal([]byte(datos), &sesion.Datos)
+	if err != nil {
+		return nil, gko.ErrAlLeer.Err(err).Op(op).Str("datos de sesión inválidos")
+	}
+	if sesion.Datos == nil {
+		sesion.Datos = map[string]string{}
+	}
+	sesion.Creada, err = time.Parse(formatoTimestamp, creada)
+	if err != nil {
+		return nil, gko.ErrAlLeer.Err(err).Op(op).Str("creada no tiene formato correcto en db")
+	}
+	sesion.Expira, err = time.Parse(formatoTimestamp, expira)
+	if err != nil {
+		return nil, gko.ErrAlLeer.Err(err).Op(op).Str("expira no tiene formato correcto en db")
+	}
+	return sesion, nil
+}
+
+func (s *SessionStore) Save(sesion *gecko.Sesion) error {
+	const op string = "sesionsqlite.Save"
+	if sesion == nil || sesion.ID == "" {
+		return gko.ErrDatoIndef.Str("pk_indefinida").Op(op).Msg("SesionID sin especificar")
+	}
+	datos, err := json.Marshal(sesion.Datos)
+	if err != nil {
+		return gko.ErrInesperado.Err(err).Op(op)
+	}
+	_, err = s.db.Exec("INSERT INTO sesiones "+
+		"(sesion_id, usuario_id, datos, creada, expira) VALUES (?, ?, ?, ?, ?) "+
+		"ON CONFLICT (sesion_id) DO UPDATE SET "+
+		"usuario_id = excluded.usuario_id, datos = excluded.datos, expira = excluded.expira",
+		sesion.ID, sesion.UsuarioID, string(datos),
+		sesion.Creada.UTC().Format(formatoTimestamp), sesion.Expira.UTC().Format(formatoTimestamp),
+	)
+	if err != nil {
+		return gko.ErrAlEscribir.Err(err).Op(op)
+	}
+	return nil
+}
+
+func (s *SessionStore) Delete(id string) error {
+	const op string = "sesionsqlite.Delete"
+	_, err := s.db.Exec("DELETE FROM sesiones WHERE sesion_id = ?", id)
+	if err != nil {
+		return gko.ErrAlEscribir.Err(err).Op(op)
+	}
+	return nil
+}
+
+func (s *SessionStore) DeleteExpired(t time.Time) error {
+	const op string = "sesionsqlite.DeleteExpired"
+	_, err := s.db.Exec("DELETE FROM sesiones WHERE expira <= ?", t.UTC().Format(formatoTimestamp))
+	if err != nil {
+		return gko.ErrAlEscribir.Err(err).Op(op)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package sesionsqlite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pargomx/gecko"
+	"github.com/pargomx/gecko/gko"
+	"github.com/pargomx/gecko/sqlitedb"
+)
+
+func nuevoStorePrueba(t *testing.T) *SessionStore {
+	t.Helper()
+	db, err := sqlitedb.NuevoRepositorio(filepath.Join(t.TempDir(), "sesiones.db"), fstest.MapFS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := NuevoSessionStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Preparar de nuevo con la tabla ya creada.
+	if _, err := NuevoSessionStore(db); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestStore(t *testing.T) {
+	store := nuevoStorePrueba(t)
+	ahora := time.Now()
+	sesion := &gecko.Sesion{ID: "sesion-1", UsuarioID: "ana", Datos: map[string]string{"tema": "oscuro"},
+		Creada: ahora, Expira: ahora.Add(time.Hour)}
+
+	if _, err := store.Get(sesion.ID); !gko.EsErrNotFound(err) {
+		t.Fatalf("sesión inexistente: %v", err)
+	}
+	if err := store.Save(&gecko.Sesion{}); err == nil {
+		t.Error("se guardó una sesión sin ID")
+	}
+	if err := store.Save(sesion); err != nil {
+		t.Fatal(err)
+	}
+	leida, err := store.Get(sesion.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leida.UsuarioID != "ana" || leida.Datos["tema"] != "oscuro" ||
+		!leida.Creada.Equal(ahora.Truncate(time.Microsecond)) || !leida.Expira.Equal(sesion.Expira.Truncate(time.Microsecond)) {
+		t.Errorf("sesión leída distinta: %+v", leida)
+	}
+
+	// Actualizar.
+	sesion.UsuarioID = "beto"
+	sesion.Expira = ahora.Add(-time.Minute)
+	if err := store.Save(sesion); err != nil {
+		t.Fatal(err)
+	}
+	if leida, err := store.Get(sesion.ID); err != nil || leida.UsuarioID != "beto" || !leida.Expirada(ahora) {
+		t.Errorf("sesión no actualizada: %+v %v", leida, err)
+	}
+
+	vigente := &gecko.Sesion{ID: "sesion-2", Creada: ahora, Expira: ahora.Add(time.Hour)}
+	if err := store.Save(vigente); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteExpired(ahora); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(sesion.ID); !gko.EsErrNotFound(err) {
+		t.Errorf("DeleteExpired no eliminó la sesión expirada: %v", err)
+	}
+	if leida, err := store.Get(vigente.ID); err != nil || leida.Datos == nil {
+		t.Errorf("sesión vigente: %+v %v", leida, err)
+	}
+	if err := store.Delete(vigente.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(vigente.ID); err != nil {
+		t.Errorf("eliminar sesión inexistente: %v", err)
+	}
+	if _, err := store.Get(vigente.ID); !gko.EsErrNotFound(err) {
+		t.Errorf("Delete no eliminó la sesión: %v", err)
+	}
+}
+
+func cookieSesion(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, ck := range rec.Result().Cookies() {
+		if ck.Name == "sesion" {
+			return ck
+		}
+	}
+	return nil
+}
+
+// Iniciar, leer, rotar y terminar con el servicio de sesiones.
+func TestServicioSesiones(t *testing.T) {
+	store := nuevoStorePrueba(t)
+	sesiones := gecko.NuevoServicioSesiones(store)
+	g := gecko.New()
+	g.Use(sesiones.Middleware)
+	g.POST("/login", func(c *gecko.Context) error {
+		_, err := sesiones.Iniciar(c, "ana")
+		if err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	g.POST("/rotar", func(c *gecko.Context) error {
+		_, err := sesiones.Rotar(c)
+		if err != nil {
+			return err
+		}
+		return c.StringOk("ok")
+	})
+	g.GET("/yo", func(c *gecko.Context) error {
+		if c.GetSesion() == nil {
+			return c.StringOk("anónimo")
+		}
+		return c.StringOk(c.GetSesion().UsuarioID)
+	})
+	g.POST("/logout", func(c *gecko.Context) error { return sesiones.Terminar(c) })
+	solicitud := func(method, ruta string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, ruta, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+
+	cookie := cookieSesion(solicitud(http.MethodPost, "/login", nil))
+	if cookie == nil {
+		t.Fatal("login sin cookie de sesión")
+	}
+	if rec := solicitud(http.MethodGet, "/yo", cookie); rec.Body.String() != "ana" {
+		t.Errorf("leer: %q", rec.Body.String())
+	}
+	sesion, err := store.Get(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sesion.Datos["tema"] = "oscuro"
+	sesion.Datos["csrf"] = "token-anterior"
+	if err := store.Save(sesion); err != nil {
+		t.Fatal(err)
+	}
+
+	rotada := cookieSesion(solicitud(http.MethodPost, "/rotar", cookie))
+	if rotada == nil || rotada.Value == cookie.Value {
+		t.Fatalf("rotar: cookie %v", rotada)
+	}
+	if _, err := store.Get(cookie.Value); !gko.EsErrNotFound(err) {
+		t.Errorf("sesión anterior sigue en el store: %v", err)
+	}
+	nueva, err := store.Get(rotada.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nueva.UsuarioID != "ana" || nueva.Datos["tema"] != "oscuro" || nueva.Datos["csrf"] != "" {
+		t.Errorf("sesión rotada: %+v", nueva)
+	}
+
+	solicitud(http.MethodPost, "/logout", rotada)
+	if _, err := store.Get(rotada.Value); !gko.EsErrNotFound(err) {
+		t.Errorf("sesión terminada sigue en el store: %v", err)
+	}
+	if rec := solicitud(http.MethodGet, "/yo", rotada); rec.Body.String() != "anónimo" {
+		t.Errorf("sesión terminada aceptada: %q", rec.Body.String())
+	}
+}
@@ -1,80 +1,320 @@
 package sqlitedb
 
 import (
+	"compress/gzip"
+	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pargomx/gecko/gko"
 	"github.com/pargomx/gecko/gkt"
 )
 
+// ================================================================ //
+// ========== BACKUPS ============================================= //
+
+// Formato de la fecha en el nombre de los backups. Con milisegundos
+// para que dos backups en el mismo segundo no choquen.
+const formatoFechaBackup = "2006-01-02_150405.000"
+
+// Formato de los backups hechos antes de usar milisegundos.
+const formatoFechaBackupSegundos = "2006-01-02_150405"
+
+// Hace un backup de la base de datos sin detenerla y verifica su
+// integridad. Ver IniciarBackups para hacerlos periódicamente.
 func (s *SqliteDB) Backup() error {
+	_, err := s.backup(s.backupsDir, "")
+	return err
+}
+
+// Hace un backup en dir con VACUUM INTO mientras la base de datos sigue
+// en uso. El sufijo, si se da, se agrega al nombre.
+// Ej: "app.db.2025-05-28_150405.123_v2.0.db". Regresa la ruta del backup.
+//
+// Un backup a la vez para que no elijan el mismo destino ni se borre
+// uno que se está escribiendo.
+func (s *SqliteDB) backup(dir string, sufijo string) (string, error) {
 	op := gko.Op("sqlitedb.Backup")
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
 
 	// Directorio para backups.
-	if s.backupsDir == "" {
-		s.backupsDir = "backups"
-	}
-	info, err := os.Stat(s.backupsDir)
+	info, err := os.Stat(dir)
 	if os.IsNotExist(err) {
-		err := os.MkdirAll(s.backupsDir, 0750)
+		err := os.MkdirAll(dir, 0750)
 		if err != nil {
-			return op.Err(err).Op("NewDatabaseDir")
+			return "", op.Err(err).Op("NewDatabaseDir")
 		}
-		gko.LogInfof("SQLiteDB: directorio creado '%v'", s.backupsDir)
+		gko.LogInfof("SQLiteDB: directorio creado '%v'", dir)
 	} else if err != nil {
-		return op.Err(err)
+		return "", op.Err(err)
 	} else if !info.IsDir() {
-		return op.Str("Directorio para backups inválido: %v")
+		return "", op.Strf("Directorio para backups inválido: %v", dir)
 	}
 
-	// Destino para el backup.
-	backupName := fmt.Sprintf("%v.%v.db", path.Base(s.dbPath), gkt.Now().Format("2006-01-02_150405"))
-	backupPath := path.Join(s.backupsDir, backupName)
-
-	// Comprobar que no exista otro archivo con el mismo nombre para el backup.
-	if _, err := os.Stat(backupPath); err == nil {
-		return op.Strf("conflicto: backup file ya existe: %v", backupPath)
-	} else if !os.IsNotExist(err) {
-		return op.Err(err).Strf("backup file ya existe? %v", backupPath)
+	// Destino para el backup que no exista todavía. Si ya hay uno con
+	// la misma fecha se agrega un contador: "app.db.<fecha>-2.db".
+	base := fmt.Sprintf("%v.%v", path.Base(s.dbPath), gkt.Now().Format(formatoFechaBackup))
+	if sufijo != "" {
+		base += "_" + sufijo
+	}
+	backupPath := path.Join(dir, base+".db")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", op.Err(err).Strf("backup file ya existe? %v", backupPath)
+		}
+		backupPath = path.Join(dir, fmt.Sprintf("%v-%d.db", base, i))
 	}
 
-	// Cerrar base de datos para que todo esté contenido en un solo archivo.
-	err = s.CloseFully()
+	// Conexión aparte para no ocupar la de escritura y porque las de
+	// lectura no pueden escribir archivos. En WAL mode no bloquea a nadie.
+	conn, err := sql.Open("sqlite", s.dbPath+configPragmaDSN)
 	if err != nil {
-		return op.Err(err)
+		return "", op.Err(err)
 	}
-
-	// Copiar archivo de base de datos.
-	dstFile, err := os.OpenFile(backupPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	defer conn.Close()
+	_, err = conn.Exec("VACUUM INTO ?", backupPath)
 	if err != nil {
-		return op.Err(err)
+		os.Remove(backupPath)
+		return "", op.Err(err).Ctx("destino", backupPath)
 	}
-	defer dstFile.Close()
-
-	srcFile, err := os.Open(s.dbPath)
+	// VACUUM INTO lo crea con el umask y tiene todos los datos.
+	err = os.Chmod(backupPath, 0600)
 	if err != nil {
-		return op.Err(err)
+		os.Remove(backupPath)
+		return "", op.Err(err).Ctx("destino", backupPath)
 	}
-	defer srcFile.Close()
 
-	_, err = srcFile.Seek(0, 0)
+	err = verificarIntegridad(backupPath)
 	if err != nil {
-		return op.Err(err)
+		os.Remove(backupPath)
+		return "", op.Err(err).Ctx("destino", backupPath)
 	}
+	gko.LogInfof("SqliteDB: backup saved '%v'", backupPath)
+	return backupPath, nil
+}
 
-	_, err = dstFile.ReadFrom(srcFile)
+// Abre el archivo de base de datos y ejecuta PRAGMA integrity_check.
+func verificarIntegridad(dbPath string) error {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=query_only(1)")
 	if err != nil {
-		return op.Err(err)
+		return err
 	}
+	defer db.Close()
+	var resultado string
+	err = db.QueryRow("PRAGMA integrity_check").Scan(&resultado)
+	if err != nil {
+		return gko.Err(err).Op("integrity_check")
+	}
+	if resultado != "ok" {
+		return gko.ErrInesperado.Strf("integrity_check: %v", resultado)
+	}
+	return nil
+}
 
-	gko.LogInfof("SqliteDB: backup saved '%v'", backupPath)
+// ================================================================ //
+// ========== BACKUPS PROGRAMADOS ================================= //
+
+// Configuración para IniciarBackups y LimpiarBackups.
+type OpcionesBackup struct {
+	Dir       string        // Default "backups".
+	Intervalo time.Duration // Cada cuánto hacer un backup. Default 24h.
+	Diarios   int           // Días con backup a conservar, el último de cada uno. Default 7.
+	Semanales int           // Semanas con backup a conservar, el último de cada una. Default 4.
+	Comprimir bool          // Comprimir con gzip los backups conservados excepto el más reciente.
+}
+
+func (o *OpcionesBackup) completar() {
+	if o.Intervalo <= 0 {
+		o.Intervalo = 24 * time.Hour
+	}
+	if o.Diarios <= 0 {
+		o.Diarios = 7
+	}
+	if o.Semanales <= 0 {
+		o.Semanales = 4
+	}
+}
+
+// Hace un backup cada cierto intervalo y después aplica la retención
+// con LimpiarBackups. Se detiene al cerrar la base de datos.
+//
+//	db.IniciarBackups(sqlitedb.OpcionesBackup{Dir: "backups", Comprimir: true})
+func (s *SqliteDB) IniciarBackups(opts OpcionesBackup) {
+	opts.completar()
+	if opts.Dir == "" {
+		opts.Dir = s.backupsDir
+	}
+	s.detenerBackups()
+	detener := make(chan struct{})
+	terminado := make(chan struct{})
+	s.backupsProgramados = func() {
+		close(detener)
+		<-terminado
+	}
+	go func() {
+		defer close(terminado)
+		ticker := time.NewTicker(opts.Intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-detener:
+				return
+			case <-ticker.C:
+			}
+			if _, err := s.backup(opts.Dir, ""); err != nil {
+				gko.Err(err).Op("sqlitedb.IniciarBackups").Log()
+				continue
+			}
+			if err := s.LimpiarBackups(opts); err != nil {
+				gko.Err(err).Op("sqlitedb.IniciarBackups").Log()
+			}
+		}
+	}()
+	gko.LogInfof("SqliteDB: backups cada %v en '%v'", opts.Intervalo, opts.Dir)
+}
 
-	// Volver a abrir db original.
-	err = s.openDatabase()
+// Detiene los backups programados y espera al que esté en curso.
+func (s *SqliteDB) detenerBackups() {
+	if s.backupsProgramados != nil {
+		s.backupsProgramados()
+		s.backupsProgramados = nil
+	}
+}
+
+// Borra los backups que no se deban conservar según las opciones y
+// comprime los conservados si así se indica. Solo considera los backups
+// sin sufijo, por lo que los hechos antes de migrar no se tocan.
+func (s *SqliteDB) LimpiarBackups(opts OpcionesBackup) error {
+	op := gko.Op("sqlitedb.LimpiarBackups")
+	opts.completar()
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+	dir := s.backupsDir
+	if opts.Dir != "" {
+		dir = opts.Dir
+	}
+	backups, err := listarBackups(dir, path.Base(s.dbPath))
 	if err != nil {
 		return op.Err(err)
 	}
+	// Del más reciente al más antiguo.
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].fecha.Equal(backups[j].fecha) {
+			return backups[i].fecha.After(backups[j].fecha)
+		}
+		return backups[i].contador > backups[j].contador
+	})
+
+	días := map[string]bool{}
+	semanas := map[string]bool{}
+	for i, bk := range backups {
+		día := bk.fecha.Format("2006-01-02")
+		año, num := bk.fecha.ISOWeek()
+		semana := fmt.Sprintf("%d-%02d", año, num)
+		conservar := false
+		if !días[día] && len(días) < opts.Diarios {
+			días[día] = true
+			conservar = true
+		}
+		if !semanas[semana] && len(semanas) < opts.Semanales {
+			semanas[semana] = true
+			conservar = true
+		}
+		ruta := path.Join(dir, bk.nombre)
+		if !conservar {
+			if err := os.Remove(ruta); err != nil {
+				return op.Err(err)
+			}
+			gko.LogInfof("SqliteDB: backup eliminado '%v'", ruta)
+			continue
+		}
+		if opts.Comprimir && i > 0 && !strings.HasSuffix(bk.nombre, ".gz") {
+			if err := comprimirBackup(ruta); err != nil {
+				return op.Err(err)
+			}
+		}
+	}
 	return nil
 }
+
+type archivoBackup struct {
+	nombre   string
+	fecha    time.Time
+	contador int // Del "-N" agregado cuando ya había otro con la misma fecha.
+}
+
+// Backups sin sufijo de la base de datos dada: "app.db.<fecha>[-N].db[.gz]".
+func listarBackups(dir string, dbName string) ([]archivoBackup, error) {
+	entradas, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	backups := []archivoBackup{}
+	for _, e := range entradas {
+		if e.IsDir() {
+			continue
+		}
+		resto, ok := strings.CutPrefix(e.Name(), dbName+".")
+		if !ok {
+			continue
+		}
+		resto = strings.TrimSuffix(resto, ".gz")
+		fecha, ok := strings.CutSuffix(resto, ".db")
+		if !ok {
+			continue
+		}
+		contador := 1
+		if i := strings.LastIndexByte(fecha, '-'); i > 0 {
+			if n, err := strconv.Atoi(fecha[i+1:]); err == nil {
+				fecha, contador = fecha[:i], n
+			}
+		}
+		t, err := time.ParseInLocation(formatoFechaBackup, fecha, gkt.Now().Location())
+		if err != nil {
+			t, err = time.ParseInLocation(formatoFechaBackupSegundos, fecha, gkt.Now().Location())
+		}
+		if err != nil {
+			continue // Con sufijo u otro formato.
+		}
+		backups = append(backups, archivoBackup{nombre: e.Name(), fecha: t, contador: contador})
+	}
+	return backups, nil
+}
+
+// Reemplaza el archivo por su versión "archivo.gz".
+func comprimirBackup(ruta string) error {
+	origen, err := os.Open(ruta)
+	if err != nil {
+		return err
+	}
+	defer origen.Close()
+	destino, err := os.OpenFile(ruta+".gz", os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(destino)
+	_, err = io.Copy(gz, origen)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = destino.Close()
+	} else {
+		destino.Close()
+	}
+	if err != nil {
+		os.Remove(ruta + ".gz")
+		return err
+	}
+	origen.Close()
+	return os.Remove(ruta)
+}
@@ -0,0 +1,107 @@
+package sqlitedb
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLimpiarBackups(t *testing.T) {
+	dir := t.TempDir()
+	s := &SqliteDB{dbPath: filepath.Join(dir, "app.db"), backupsDir: dir}
+	archivos := []string{
+		"app.db.2025-06-02_100000.000.db",   // Lunes, semana 23.
+		"app.db.2025-06-02_100000.000-2.db", // Mismo milisegundo, más reciente.
+		"app.db.2025-06-01_100000.000.db",   // Domingo, semana 22.
+		"app.db.2025-06-01_080000.db",       // Formato anterior en segundos.
+		"app.db.2025-05-31_100000.000.db",
+		"app.db.2025-05-20_100000.000.db", // Semana 21.
+		"app.db.2025-05-10_100000.000.db", // Semana 19, fuera de la retención.
+		"app.db.2025-05-10_100000.000_v2.0.db",
+		"otra.db.2025-05-10_100000.000.db",
+	}
+	for _, nombre := range archivos {
+		if err := os.WriteFile(filepath.Join(dir, nombre), []byte(nombre), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := s.LimpiarBackups(OpcionesBackup{Diarios: 2, Semanales: 3, Comprimir: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quedan := []string{
+		"app.db.2025-05-10_100000.000_v2.0.db", // Con sufijo no se toca.
+		"app.db.2025-05-20_100000.000.db.gz",
+		"app.db.2025-06-01_100000.000.db.gz",
+		"app.db.2025-06-02_100000.000-2.db", // El más reciente sin comprimir.
+		"otra.db.2025-05-10_100000.000.db",
+	}
+	if got := listarDir(t, dir); !slices.Equal(got, quedan) {
+		t.Errorf("quedan %v\nse esperaba %v", got, quedan)
+	}
+
+	// Volver a limpiar no cambia nada.
+	if err := s.LimpiarBackups(OpcionesBackup{Diarios: 2, Semanales: 3, Comprimir: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := listarDir(t, dir); !slices.Equal(got, quedan) {
+		t.Errorf("segunda limpieza: %v", got)
+	}
+}
+
+func TestBackupsMismoMomento(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	dir := t.TempDir()
+	rutas := map[string]bool{}
+	for range 3 {
+		ruta, err := db.backup(dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rutas[ruta] = true
+		info, err := os.Stat(ruta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("permisos del backup: %v", info.Mode().Perm())
+		}
+	}
+	if len(rutas) != 3 {
+		t.Fatalf("backups con la misma ruta: %v", rutas)
+	}
+	backups, err := listarBackups(dir, "prueba.db")
+	if err != nil || len(backups) != 3 {
+		t.Fatalf("listarBackups: %v %v", backups, err)
+	}
+
+	db.IniciarBackups(OpcionesBackup{Dir: dir, Intervalo: 10 * time.Millisecond})
+	for range 200 {
+		if backups, _ := listarBackups(dir, "prueba.db"); len(backups) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	db.Close() // Debe esperar al backup en curso.
+	// La retención deja solo el último del día, ya no uno de los manuales.
+	backups, err = listarBackups(dir, "prueba.db")
+	if err != nil || len(backups) != 1 || rutas[filepath.Join(dir, backups[0].nombre)] {
+		t.Fatalf("después de backups programados: %v %v", backups, err)
+	}
+}
+
+func listarDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entradas, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nombres := []string{}
+	for _, e := range entradas {
+		nombres = append(nombres, e.Name())
+	}
+	return nombres
+}
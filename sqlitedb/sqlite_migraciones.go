@@ -275,7 +275,8 @@ func (s *SqliteDB) upgradeVersionEsquema(migEsquema migracionDisponible, migDato
 			migEsquema.major, migEsquema.minor, migEsquema.major, migDatos.major, migDatos.minor)
 	}
 
-	err := s.Backup() // TODO: muchas migraciones conflicto en el mismo segundo? agregar sufijo con migracion_id mejor
+	// El sufijo con la versión distingue backups de varias migraciones en el mismo segundo.
+	_, err := s.backup(s.backupsDir, fmt.Sprintf("v%d.%d", migEsquema.major, migEsquema.minor))
 	if err != nil {
 		return op.Err(err)
 	}
@@ -296,7 +297,7 @@ func (s *SqliteDB) upgradeVersionEsquema(migEsquema migracionDisponible, migDato
 	// Al final siempre se debe volver la conexión a la ruta original.
 	oldDatabasePath := s.dbPath
 	defer func() {
-		s.db.Close()
+		s.cerrarConexiones()
 		s.dbPath = oldDatabasePath
 		s.openDatabase()
 		os.Remove(newTempPath)
@@ -4,35 +4,77 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+
+	"github.com/pargomx/gecko/gko"
 )
 
 type Transaccion struct {
 	tx  *sql.Tx
 	log bool
+
+	savepoint   string       // Nombre del SAVEPOINT si es anidada. Ver WithTx.
+	padre       *Transaccion // Transacción que contiene al savepoint.
+	alConfirmar []func()     // Ver AlConfirmar.
 }
 
 // ================================================================ //
 // ================================================================ //
 
 func (s *SqliteDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), query, args...)
+}
+
+func (s *SqliteDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.QueryRowContext(context.Background(), query, args...)
+}
+
+func (s *SqliteDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), query, args...)
+}
+
+// Como Query pero se interrumpe al cancelar el contexto con gko.ErrTimeout.
+func (s *SqliteDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	if s.log {
 		logSQL(tipoQuery, query, args...)
 	}
-	return s.db.QueryContext(context.Background(), query, args...)
+	rows, err := s.conexión(query).QueryContext(ctx, query, args...)
+	return rows, errContexto(err)
 }
 
-func (s *SqliteDB) QueryRow(query string, args ...interface{}) *sql.Row {
+// Como QueryRow pero se interrumpe al cancelar el contexto. El error
+// lo da Scan y gko.Err lo convierte en gko.ErrTimeout.
+func (s *SqliteDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	if s.log {
 		logSQL(tipoQueryRow, query, args...)
 	}
-	return s.db.QueryRow(query, args...)
+	return s.conexión(query).QueryRowContext(ctx, query, args...)
 }
 
-func (s *SqliteDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+// Como Exec pero se interrumpe al cancelar el contexto con gko.ErrTimeout.
+func (s *SqliteDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	if s.log {
 		logSQL(tipoExec, query, args...)
 	}
-	return s.db.Exec(query, args...)
+	res, err := s.db.ExecContext(ctx, query, args...)
+	return res, errContexto(err)
+}
+
+// Las consultas SELECT van a las conexiones de lectura. Cualquier otra,
+// como un INSERT ... RETURNING hecho con QueryRow, va a la de escritura.
+func (s *SqliteDB) conexión(query string) *sql.DB {
+	if s.lectura != nil && esLectura(query) {
+		return s.lectura
+	}
+	return s.db
+}
+
+func esLectura(query string) bool {
+	query = strings.TrimSpace(query)
+	if len(query) < 6 {
+		return false
+	}
+	return strings.EqualFold(query[:6], "SELECT")
 }
 
 // ================================================================ //
@@ -66,12 +108,17 @@ func (s *SqliteDB) ExecInTransaction(query string, args ...interface{}) (sql.Res
 // ================================================================ //
 
 func (s *SqliteDB) Begin() (*Transaccion, error) {
+	return s.BeginContext(context.Background())
+}
+
+// Inicia una transacción que se revierte si se cancela el contexto.
+func (s *SqliteDB) BeginContext(ctx context.Context) (*Transaccion, error) {
 	if s.log {
 		logSQL(tipoTX, "BEGIN TRANSACTION")
 	}
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return nil, errContexto(err)
 	}
 	return &Transaccion{
 		tx:  tx,
@@ -80,13 +127,26 @@ func (s *SqliteDB) Begin() (*Transaccion, error) {
 }
 
 func (s *Transaccion) Commit() error {
+	if s.savepoint != "" {
+		return s.liberarSavepoint()
+	}
 	if s.log {
 		logSQL(tipoTX, "COMMIT")
 	}
-	return s.tx.Commit()
+	err := s.tx.Commit()
+	if err != nil {
+		return err
+	}
+	for _, fn := range s.alConfirmar {
+		fn()
+	}
+	return nil
 }
 
 func (s *Transaccion) Rollback() error {
+	if s.savepoint != "" {
+		return s.revertirSavepoint()
+	}
 	if s.log {
 		logSQL(tipoTX, "ROLLBACK")
 	}
@@ -94,22 +154,85 @@ func (s *Transaccion) Rollback() error {
 }
 
 func (s *Transaccion) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), query, args...)
+}
+
+func (s *Transaccion) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.QueryRowContext(context.Background(), query, args...)
+}
+
+func (s *Transaccion) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), query, args...)
+}
+
+func (s *Transaccion) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	if s.log {
 		logSQL(tipoQuery, query, args...)
 	}
-	return s.tx.QueryContext(context.Background(), query, args...)
+	rows, err := s.tx.QueryContext(ctx, query, args...)
+	return rows, errContexto(err)
 }
 
-func (s *Transaccion) QueryRow(query string, args ...interface{}) *sql.Row {
+func (s *Transaccion) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	if s.log {
 		logSQL(tipoQueryRow, query, args...)
 	}
-	return s.tx.QueryRowContext(context.Background(), query, args...)
+	return s.tx.QueryRowContext(ctx, query, args...)
 }
 
-func (s *Transaccion) Exec(query string, args ...interface{}) (sql.Result, error) {
+func (s *Transaccion) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	if s.log {
 		logSQL(tipoExec, query, args...)
 	}
-	return s.tx.Exec(query, args...)
+	res, err := s.tx.ExecContext(ctx, query, args...)
+	return res, errContexto(err)
+}
+
+// ================================================================ //
+// ========== CONTEXTO ============================================ //
+
+// Ejecutor que usa el contexto dado en todas sus operaciones para que
+// los repositorios se detengan cuando se cancele, por ejemplo, cuando
+// el cliente abandona la solicitud HTTP:
+//
+//	repo := NuevoRepoUsuarios(s.db.ConContexto(c.Request().Context()))
+func (s *SqliteDB) ConContexto(ctx context.Context) Ejecutor {
+	return &ejecutorConContexto{ctx: ctx, ejecutor: s}
+}
+
+// Ejecutor para la transacción que usa el contexto dado en todas sus operaciones.
+func (s *Transaccion) ConContexto(ctx context.Context) Ejecutor {
+	return &ejecutorConContexto{ctx: ctx, ejecutor: s}
+}
+
+type ejecutorConContexto struct {
+	ctx      context.Context
+	ejecutor Ejecutor
+}
+
+func (e *ejecutorConContexto) Query(query string, args ...any) (*sql.Rows, error) {
+	return e.ejecutor.QueryContext(e.ctx, query, args...)
+}
+func (e *ejecutorConContexto) QueryRow(query string, args ...any) *sql.Row {
+	return e.ejecutor.QueryRowContext(e.ctx, query, args...)
+}
+func (e *ejecutorConContexto) Exec(query string, args ...any) (sql.Result, error) {
+	return e.ejecutor.ExecContext(e.ctx, query, args...)
+}
+func (e *ejecutorConContexto) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return e.ejecutor.QueryContext(ctx, query, args...)
+}
+func (e *ejecutorConContexto) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return e.ejecutor.QueryRowContext(ctx, query, args...)
+}
+func (e *ejecutorConContexto) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return e.ejecutor.ExecContext(ctx, query, args...)
+}
+
+// Convierte la cancelación o el vencimiento del contexto en gko.ErrTimeout.
+func errContexto(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return gko.ErrTimeout.Err(err).Msg("La operación fue cancelada o tardó demasiado")
+	}
+	return err
 }
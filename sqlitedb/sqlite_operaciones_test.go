@@ -0,0 +1,47 @@
+package sqlitedb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+func TestContextoCancelado(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := db.QueryContext(ctx, "SELECT id FROM items"); !gko.Is(err, gko.ErrTimeout) {
+		t.Errorf("QueryContext: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM items"); !gko.Is(err, gko.ErrTimeout) {
+		t.Errorf("ExecContext: %v", err)
+	}
+	if _, err := db.ConContexto(ctx).Exec("DELETE FROM items"); !gko.Is(err, gko.ErrTimeout) {
+		t.Errorf("ConContexto.Exec: %v", err)
+	}
+	var n int
+	if err := db.ConContexto(ctx).QueryRow("SELECT count(*) FROM items").Scan(&n); !gko.Is(gko.Err(err), gko.ErrTimeout) {
+		t.Errorf("ConContexto.QueryRow: %v", err)
+	}
+	if _, err := db.BeginContext(ctx); !gko.Is(err, gko.ErrTimeout) {
+		t.Errorf("BeginContext: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ConContexto(ctx).Exec("DELETE FROM items"); !gko.Is(err, gko.ErrTimeout) {
+		t.Errorf("Transaccion.ConContexto.Exec: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nada se borró.
+	if err := db.QueryRow("SELECT count(*) FROM items").Scan(&n); err != nil || n != 1000 {
+		t.Errorf("registros: %d, %v", n, err)
+	}
+}
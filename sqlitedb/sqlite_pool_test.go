@@ -0,0 +1,115 @@
+package sqlitedb
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// Base de datos con una tabla de 1000 registros para las pruebas.
+func nuevaDBPrueba(tb testing.TB) *SqliteDB {
+	tb.Helper()
+	db, err := NuevoRepositorio(filepath.Join(tb.TempDir(), "prueba.db"), fstest.MapFS{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	err = db.WithTx(func(tx *Transaccion) error {
+		_, err := tx.Exec("CREATE TABLE items (id INT PRIMARY KEY, nombre TEXT NOT NULL)")
+		if err != nil {
+			return err
+		}
+		for i := range 1000 {
+			_, err := tx.Exec("INSERT INTO items VALUES (?, ?)", i, "item")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
+
+func TestLecturaNoModifica(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	if _, err := db.lectura.Exec("DELETE FROM items"); err == nil {
+		t.Fatal("conexión de lectura pudo modificar la base de datos")
+	}
+	// QueryRow con RETURNING debe ir a la conexión de escritura.
+	var id int
+	err := db.QueryRow("INSERT INTO items VALUES (5000, 'nuevo') RETURNING id").Scan(&id)
+	if err != nil || id != 5000 {
+		t.Fatalf("insert returning: id=%v err=%v", id, err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM items").Scan(&n); err != nil || n != 1001 {
+		t.Fatalf("lectura después de escribir: n=%v err=%v", n, err)
+	}
+}
+
+const queryBenchmark = "SELECT count(*) FROM items WHERE nombre = ?"
+
+// Lecturas en paralelo con las conexiones de lectura.
+func BenchmarkLecturasConcurrentes(b *testing.B) {
+	db := nuevaDBPrueba(b)
+	b.RunParallel(func(pb *testing.PB) {
+		var n int
+		for pb.Next() {
+			if err := db.QueryRow(queryBenchmark, "item").Scan(&n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Las mismas lecturas con solo la conexión de escritura, como antes.
+func BenchmarkLecturasConcurrentesUnaConexion(b *testing.B) {
+	db := nuevaDBPrueba(b)
+	b.RunParallel(func(pb *testing.PB) {
+		var n int
+		for pb.Next() {
+			if err := db.db.QueryRow(queryBenchmark, "item").Scan(&n); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// Lecturas en paralelo mientras otra goroutine escribe sin parar.
+func BenchmarkLecturasConEscrituras(b *testing.B) {
+	db := nuevaDBPrueba(b)
+	terminar := make(chan struct{})
+	escritor := make(chan error, 1)
+	go func() {
+		for i := 10_000; ; i++ {
+			select {
+			case <-terminar:
+				escritor <- nil
+				return
+			default:
+			}
+			if _, err := db.Exec("INSERT INTO items VALUES (?, 'otro')", i); err != nil {
+				escritor <- err
+				return
+			}
+		}
+	}()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var n int
+		for pb.Next() {
+			if err := db.QueryRow(queryBenchmark, "item").Scan(&n); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	close(terminar)
+	if err := <-escritor; err != nil {
+		b.Fatal(err)
+	}
+}
@@ -1,10 +1,14 @@
 package sqlitedb
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"io/fs"
 	"os"
 	"path"
+	"runtime"
+	"sync"
 
 	_ "github.com/glebarez/go-sqlite"
 	"github.com/pargomx/gecko/gko"
@@ -15,19 +19,48 @@ import (
 // Hay configuraciones que se aplican mediante SQL a la base de datos.
 var configPragmaDSN = "?_pragma=foreign_keys(1)&_busy_timeout=1000"
 
+// Las transacciones de escritura toman el lock desde BEGIN para no
+// fallar con "database locked" al pasar de lectura a escritura.
+const configEscritorDSN = "&_txlock=immediate"
+
+// Las conexiones de lectura no pueden modificar la base de datos.
+const configLectorDSN = "&_pragma=query_only(1)"
+
+// Máximo de conexiones de lectura simultáneas. En WAL mode las lecturas
+// no se bloquean entre sí ni con la escritura.
+var maxLectores = max(4, runtime.NumCPU())
+
 // Wrapper para "database/sql" con sqlite que permite loggear sentencias.
+//
+// Usa una sola conexión para escribir y varias para leer, aprovechando
+// el WAL mode para que las lecturas no esperen a las escrituras.
 type SqliteDB struct {
-	dbPath     string // ruta al archivo de base de datos.
-	db         *sql.DB
-	backupsDir string // directorio en donde poner backups de base de datos.
+	dbPath     string  // ruta al archivo de base de datos.
+	db         *sql.DB // Conexión única para escribir y transacciones.
+	lectura    *sql.DB // Conexiones de solo lectura para Query y QueryRow.
+	backupsDir string  // directorio en donde poner backups de base de datos.
 	log        bool
+
+	backupMu           sync.Mutex // Un backup o limpieza a la vez.
+	backupsProgramados func()     // Detiene los backups de IniciarBackups.
 }
 
 // Utilizado para que los repositorios del dominio puedan usar DB o Transaccion.
+//
+// Las variantes con contexto se interrumpen al cancelarlo con gko.ErrTimeout.
+// Ver ConContexto para que las variantes sin contexto también lo usen.
+//
+// Las implementaciones propias de Ejecutor, como mocks en las pruebas de
+// los repositorios, deben agregar también las variantes con contexto.
+// *sql.DB, *sql.Tx y *sql.Conn ya las tienen.
 type Ejecutor interface {
 	Query(query string, args ...any) (*sql.Rows, error)
 	QueryRow(query string, args ...any) *sql.Row
 	Exec(query string, args ...any) (sql.Result, error)
+
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
 // ================================================================ //
@@ -40,13 +73,24 @@ func (s *SqliteDB) ToggleLog() {
 // Cerrar base de datos.
 func (s *SqliteDB) Close() error {
 	op := gko.Op("sqlitedb.Close")
-	err := s.db.Close()
+	s.detenerBackups()
+	err := s.cerrarConexiones()
 	if err != nil {
 		return op.Err(err)
 	}
 	return nil
 }
 
+// Cierra primero las conexiones de lectura para que la de escritura,
+// al ser la última, haga el checkpoint del WAL.
+func (s *SqliteDB) cerrarConexiones() error {
+	var errLectura error
+	if s.lectura != nil {
+		errLectura = s.lectura.Close()
+	}
+	return errors.Join(errLectura, s.db.Close())
+}
+
 // Cerrar base de datos y comprobar que todo esté contenido en un solo archivo.
 // En WAL mode para un archivo "app.db" se generan "app.db-shm" y "app.db-wal".
 // Si aún están estos archivos puede que algo los mantenga abiertos y por lo tanto
@@ -54,7 +98,7 @@ func (s *SqliteDB) Close() error {
 // el archivo de base de datos.
 func (s *SqliteDB) CloseFully() error {
 	op := gko.Op("sqlitedb.CloseFully")
-	err := s.db.Close()
+	err := s.cerrarConexiones()
 	if err != nil {
 		return op.Err(err)
 	}
@@ -71,10 +115,20 @@ func (s *SqliteDB) CloseFully() error {
 // Confía en que el dbPath ya se comprobó.
 func (s *SqliteDB) openDatabase() error {
 	var err error
-	s.db, err = sql.Open("sqlite", s.dbPath+configPragmaDSN)
+	s.db, err = sql.Open("sqlite", s.dbPath+configPragmaDSN+configEscritorDSN)
 	if err != nil {
 		return err
 	}
+	// Para evitar error database locked. https://github.com/mattn/go-sqlite3/issues/274
+	s.db.SetMaxOpenConns(1)
+
+	s.lectura, err = sql.Open("sqlite", s.dbPath+configPragmaDSN+configLectorDSN)
+	if err != nil {
+		s.db.Close()
+		return err
+	}
+	s.lectura.SetMaxOpenConns(maxLectores)
+	s.lectura.SetMaxIdleConns(maxLectores)
 	return nil
 }
 
@@ -114,7 +168,7 @@ func NuevoRepositorio(dbPath string, migracionesFS fs.FS) (*SqliteDB, error) {
 	}
 
 	// Abrir repositorio.
-	repo := &SqliteDB{dbPath: dbPath, db: nil}
+	repo := &SqliteDB{dbPath: dbPath, db: nil, backupsDir: "backups"}
 	err = repo.openDatabase()
 	if err != nil {
 		return nil, op.Err(err)
@@ -133,8 +187,5 @@ func NuevoRepositorio(dbPath string, migracionesFS fs.FS) (*SqliteDB, error) {
 	// sqliteDB.QueryRow("PRAGMA busy_timeout").Scan(&pragma)
 	// fmt.Println("busy_timeout: ", pragma)
 
-	// Para evitar error database locked. https://github.com/mattn/go-sqlite3/issues/274
-	repo.db.SetMaxOpenConns(1)
-
 	return repo, nil
 }
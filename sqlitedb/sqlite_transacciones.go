@@ -0,0 +1,124 @@
+package sqlitedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== TRANSACCIONES ======================================= //
+
+// Ejecuta fn dentro de una transacción que se confirma si fn regresa
+// nil y se revierte si regresa error o entra en pánico.
+//
+// Con la misma tx se pueden crear los repositorios del dominio y el de
+// eventos para que los cambios y los eventos se guarden juntos o ninguno:
+//
+//	err := s.db.WithTx(func(tx *sqlitedb.Transaccion) error {
+//		eventos := s.eventos.EventStoreTx(tx, s.hub)
+//		err := NuevoRepoUsuarios(tx).Insert(usuario)
+//		if err != nil {
+//			return err
+//		}
+//		_, err = eventos.Rise(resp, EvUsuarioRegistrado, ev)
+//		return err
+//	})
+func (s *SqliteDB) WithTx(fn func(tx *Transaccion) error) error {
+	return s.WithTxContext(context.Background(), fn)
+}
+
+// Como WithTx pero la transacción se revierte si se cancela el contexto.
+func (s *SqliteDB) WithTxContext(ctx context.Context, fn func(tx *Transaccion) error) error {
+	tx, err := s.BeginContext(ctx)
+	if err != nil {
+		return gko.Err(err).Op("sqlitedb.WithTx")
+	}
+	return tx.ejecutar(fn)
+}
+
+// Ejecuta fn en una transacción anidada usando un SAVEPOINT. Si fn
+// regresa error o entra en pánico solo se revierte lo hecho dentro de
+// ella y la transacción exterior puede continuar.
+func (s *Transaccion) WithTx(fn func(tx *Transaccion) error) error {
+	sub := &Transaccion{
+		tx:        s.tx,
+		log:       s.log,
+		savepoint: fmt.Sprintf("sp%d", s.profundidad()+1),
+		padre:     s,
+	}
+	if s.log {
+		logSQL(tipoTX, "SAVEPOINT "+sub.savepoint)
+	}
+	if _, err := s.tx.Exec("SAVEPOINT " + sub.savepoint); err != nil {
+		return gko.Err(errContexto(err)).Op("sqlitedb.WithTx")
+	}
+	return sub.ejecutar(fn)
+}
+
+// Registra una función para ejecutar después de que la transacción
+// exterior se confirme, por ejemplo para notificar a otros lo que se
+// guardó. No se ejecuta si se revierte.
+func (s *Transaccion) AlConfirmar(fn func()) {
+	s.alConfirmar = append(s.alConfirmar, fn)
+}
+
+// Ejecuta fn y confirma o revierte según el resultado.
+func (s *Transaccion) ejecutar(fn func(tx *Transaccion) error) (err error) {
+	op := gko.Op("sqlitedb.WithTx")
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+		if errRollback := s.Rollback(); errRollback != nil {
+			op.Err(errRollback).Str("rollback después de panic").Log()
+		}
+		panic(rec)
+	}()
+	err = fn(s)
+	if err != nil {
+		if errRollback := s.Rollback(); errRollback != nil {
+			// Conservar la clave y mensajes del error de fn.
+			return gko.Err(err).Op("sqlitedb.WithTx").Ctx("rollback", errRollback)
+		}
+		return err
+	}
+	if err = s.Commit(); err != nil {
+		return op.Err(errContexto(err))
+	}
+	return nil
+}
+
+func (s *Transaccion) profundidad() int {
+	n := 0
+	for tx := s; tx.padre != nil; tx = tx.padre {
+		n++
+	}
+	return n
+}
+
+// Confirma el savepoint pasando sus funciones AlConfirmar a la transacción padre.
+func (s *Transaccion) liberarSavepoint() error {
+	if s.log {
+		logSQL(tipoTX, "RELEASE "+s.savepoint)
+	}
+	if _, err := s.tx.Exec("RELEASE " + s.savepoint); err != nil {
+		return err
+	}
+	s.padre.alConfirmar = append(s.padre.alConfirmar, s.alConfirmar...)
+	return nil
+}
+
+// Revierte lo hecho desde el savepoint y lo libera.
+func (s *Transaccion) revertirSavepoint() error {
+	if s.log {
+		logSQL(tipoTX, "ROLLBACK TO "+s.savepoint)
+	}
+	if _, err := s.tx.Exec("ROLLBACK TO " + s.savepoint); err != nil {
+		return err
+	}
+	_, err := s.tx.Exec("RELEASE " + s.savepoint)
+	return err
+}
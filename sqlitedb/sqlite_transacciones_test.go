@@ -0,0 +1,177 @@
+package sqlitedb
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// Registros con id >= 5000, que son los que insertan estas pruebas.
+func nuevos(t *testing.T, db *SqliteDB) []int {
+	t.Helper()
+	rows, err := db.Query("SELECT id FROM items WHERE id >= 5000 ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func insertar(tx *Transaccion, id int) error {
+	_, err := tx.Exec("INSERT INTO items VALUES (?, 'nuevo')", id)
+	return err
+}
+
+func TestWithTx(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	var confirmados []string
+	err := db.WithTx(func(tx *Transaccion) error {
+		tx.AlConfirmar(func() { confirmados = append(confirmados, "commit") })
+		return insertar(tx, 5000)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nuevos(t, db); !slices.Equal(got, []int{5000}) {
+		t.Errorf("commit: %v", got)
+	}
+
+	errFn := gko.ErrYaExiste.Msg("ya existe")
+	err = db.WithTx(func(tx *Transaccion) error {
+		tx.AlConfirmar(func() { confirmados = append(confirmados, "rollback") })
+		if err := insertar(tx, 5001); err != nil {
+			return err
+		}
+		return errFn
+	})
+	if !gko.Is(err, gko.ErrYaExiste) {
+		t.Errorf("rollback debe conservar el error de fn: %v", err)
+	}
+	if got := nuevos(t, db); !slices.Equal(got, []int{5000}) {
+		t.Errorf("rollback: %v", got)
+	}
+	if !slices.Equal(confirmados, []string{"commit"}) {
+		t.Errorf("AlConfirmar: %v", confirmados)
+	}
+}
+
+func TestWithTxAnidada(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	var confirmados []string
+	err := db.WithTx(func(tx *Transaccion) error {
+		tx.AlConfirmar(func() { confirmados = append(confirmados, "exterior") })
+		if err := insertar(tx, 5000); err != nil {
+			return err
+		}
+		// Confirmada: sus hooks pasan a la exterior.
+		err := tx.WithTx(func(sub *Transaccion) error {
+			sub.AlConfirmar(func() { confirmados = append(confirmados, "liberada") })
+			if err := insertar(sub, 5001); err != nil {
+				return err
+			}
+			// Dos niveles.
+			return sub.WithTx(func(sub2 *Transaccion) error {
+				sub2.AlConfirmar(func() { confirmados = append(confirmados, "liberada2") })
+				return insertar(sub2, 5002)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if len(confirmados) != 0 {
+			t.Errorf("AlConfirmar antes del commit exterior: %v", confirmados)
+		}
+		// Revertida: la exterior continúa sin sus cambios ni hooks.
+		err = tx.WithTx(func(sub *Transaccion) error {
+			sub.AlConfirmar(func() { confirmados = append(confirmados, "revertida") })
+			if err := insertar(sub, 5003); err != nil {
+				return err
+			}
+			err := sub.WithTx(func(sub2 *Transaccion) error {
+				sub2.AlConfirmar(func() { confirmados = append(confirmados, "revertida2") })
+				return insertar(sub2, 5004)
+			})
+			if err != nil {
+				return err
+			}
+			return errors.New("falla")
+		})
+		if err == nil {
+			t.Error("la transacción anidada debe regresar su error")
+		}
+		return insertar(tx, 5005)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nuevos(t, db); !slices.Equal(got, []int{5000, 5001, 5002, 5005}) {
+		t.Errorf("registros: %v", got)
+	}
+	if !slices.Equal(confirmados, []string{"exterior", "liberada", "liberada2"}) {
+		t.Errorf("AlConfirmar: %v", confirmados)
+	}
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db := nuevaDBPrueba(t)
+	confirmado := false
+	func() {
+		defer func() {
+			if rec := recover(); rec != "falla" {
+				t.Errorf("el panic debe continuar: %v", rec)
+			}
+		}()
+		db.WithTx(func(tx *Transaccion) error {
+			tx.AlConfirmar(func() { confirmado = true })
+			if err := insertar(tx, 5000); err != nil {
+				return err
+			}
+			panic("falla")
+		})
+	}()
+	if got := nuevos(t, db); len(got) != 0 || confirmado {
+		t.Errorf("panic: registros %v, AlConfirmar %v", got, confirmado)
+	}
+
+	// Panic en la anidada revierte solo el savepoint si la exterior lo recupera.
+	err := db.WithTx(func(tx *Transaccion) error {
+		if err := insertar(tx, 5001); err != nil {
+			return err
+		}
+		func() {
+			defer func() { recover() }()
+			tx.WithTx(func(sub *Transaccion) error {
+				sub.AlConfirmar(func() { confirmado = true })
+				if err := insertar(sub, 5002); err != nil {
+					return err
+				}
+				panic("falla")
+			})
+		}()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nuevos(t, db); !slices.Equal(got, []int{5001}) || confirmado {
+		t.Errorf("panic anidado: registros %v, AlConfirmar %v", got, confirmado)
+	}
+
+	// La conexión de escritura quedó libre.
+	if _, err := db.Exec("INSERT INTO items VALUES (5010, 'x')"); err != nil {
+		t.Errorf("escribir después de los panic: %v", err)
+	}
+}
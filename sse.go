@@ -0,0 +1,328 @@
+package gecko
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== SERVER-SENT EVENTS ================================== //
+
+// Intervalo default para mandar comentarios que mantengan viva la conexión
+// a través de proxies que cortan conexiones inactivas.
+const defaultKeepAliveSSE = 15 * time.Second
+
+// Mensaje para enviar por un stream SSE.
+type MensajeSSE struct {
+	ID     string        // Opcional. El navegador lo reenvía en Last-Event-ID al reconectar.
+	Evento string        // Opcional. Nombre del evento. Con htmx se usa en sse-swap="nombre".
+	Data   string        // Puede tener varias líneas.
+	Retry  time.Duration // Opcional. Tiempo que espera el navegador antes de reconectar.
+}
+
+// StreamSSE escribe eventos text/event-stream al cliente.
+//
+// Es seguro usarlo desde varias goroutines. Mientras está abierto
+// manda comentarios periódicos para mantener viva la conexión.
+// Se cierra cuando el cliente se desconecta, cuando el servidor se
+// apaga o cuando el handler termina.
+type StreamSSE struct {
+	c       *Context
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	cerrado bool
+}
+
+// Inicia un stream de Server-Sent Events enviando los headers al cliente.
+// El handler debe mantenerse en ejecución mientras quiera enviar eventos
+// y salir cuando sse.Done() se cierre.
+//
+//	sse, err := c.SSE()
+//	if err != nil {
+//		return err
+//	}
+//	for {
+//		select {
+//		case <-sse.Done():
+//			return nil
+//		case msg := <-mensajes:
+//			sse.Enviar(msg)
+//		}
+//	}
+func (c *Context) SSE() (*StreamSSE, error) {
+	return c.SSEKeepAlive(defaultKeepAliveSSE)
+}
+
+// Como c.SSE() pero con otro intervalo para los comentarios keep-alive.
+// Con intervalo cero o negativo no se envían.
+func (c *Context) SSEKeepAlive(intervalo time.Duration) (*StreamSSE, error) {
+	op := gko.Op("gecko.SSE")
+	if c.sse != nil {
+		return c.sse, nil
+	}
+	if c.response.Committed {
+		return nil, op.E(gko.ErrInesperado).Str("respuesta ya enviada")
+	}
+	if _, ok := c.response.Writer.(http.Flusher); !ok {
+		return nil, op.E(gko.ErrNoDisponible).Str("response writer no implementa http.Flusher")
+	}
+	h := c.response.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-store")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // Evitar buffer en nginx.
+	h.Del("Content-Length")
+	c.response.WriteHeader(http.StatusOK)
+	c.response.Flush()
+
+	ctx, cancel := context.WithCancel(c.request.Context())
+	s := &StreamSSE{
+		c:      c,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	c.sse = s
+
+	// Cerrar al apagar el servidor para no retrasar el graceful shutdown.
+	apagando := c.gecko.getApagandoChan()
+	go func() {
+		select {
+		case <-apagando:
+			s.cerrar()
+		case <-ctx.Done():
+		}
+	}()
+
+	if intervalo > 0 {
+		go s.keepAlive(intervalo)
+	}
+	return s, nil
+}
+
+// Se cierra cuando el cliente se desconecta, el servidor se apaga
+// o se llama sse.Cerrar().
+func (s *StreamSSE) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Contexto del stream que se cancela junto con sse.Done().
+func (s *StreamSSE) Context() context.Context {
+	return s.ctx
+}
+
+// Envía un mensaje completo al cliente.
+func (s *StreamSSE) Enviar(msg MensajeSSE) error {
+	var b strings.Builder
+	if msg.ID != "" {
+		b.WriteString("id: " + limpiarCampoSSE(msg.ID) + "\n")
+	}
+	if msg.Evento != "" {
+		b.WriteString("event: " + limpiarCampoSSE(msg.Evento) + "\n")
+	}
+	if msg.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(msg.Retry.Milliseconds(), 10) + "\n")
+	}
+	data := strings.ReplaceAll(msg.Data, "\r\n", "\n")
+	for _, línea := range strings.Split(data, "\n") {
+		b.WriteString("data: " + línea + "\n")
+	}
+	b.WriteString("\n")
+	return s.escribir(b.String())
+}
+
+// Envía un evento con nombre. Para htmx el data es el HTML a insertar.
+func (s *StreamSSE) Evento(nombre, data string) error {
+	return s.Enviar(MensajeSSE{Evento: nombre, Data: data})
+}
+
+// Indica al navegador cuánto esperar antes de reconectar si se corta.
+func (s *StreamSSE) Retry(d time.Duration) error {
+	return s.escribir("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n")
+}
+
+// Envía un comentario que el navegador ignora.
+func (s *StreamSSE) Comentario(txt string) error {
+	return s.escribir(": " + limpiarCampoSSE(txt) + "\n\n")
+}
+
+// Termina el stream. El handler debe retornar después de llamarlo.
+func (s *StreamSSE) Cerrar() {
+	s.cerrar()
+}
+
+// ID del último evento que recibió el cliente antes de reconectar.
+func (s *StreamSSE) LastEventID() string {
+	return s.c.request.Header.Get("Last-Event-ID")
+}
+
+func (s *StreamSSE) escribir(txt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cerrado || s.ctx.Err() != nil {
+		return gko.ErrNoDisponible.Str("gecko.SSE: stream cerrado")
+	}
+	_, err := s.c.response.Write([]byte(txt))
+	if err != nil {
+		s.cancel()
+		return gko.ErrAlEscribir.Err(err).Op("gecko.SSE")
+	}
+	s.c.response.Flush()
+	return nil
+}
+
+func (s *StreamSSE) cerrar() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cerrado = true
+	s.cancel()
+}
+
+func (s *StreamSSE) keepAlive(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.escribir(":\n\n") != nil {
+				return
+			}
+		}
+	}
+}
+
+// Los campos id, event y comentarios no pueden tener saltos de línea.
+func limpiarCampoSSE(txt string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(txt)
+}
+
+// ================================================================ //
+// ========== HUB SSE ============================================= //
+
+// Tema al que HubSSE publica los gko.Event recibidos por Broadcast.
+const TemaEventosSSE = "eventos"
+
+// Mensajes que puede acumular un suscriptor lento antes de que
+// se descarten los nuevos para no bloquear al que publica.
+const bufferSuscriptorSSE = 32
+
+// HubSSE distribuye mensajes a los clientes suscritos a uno o más temas.
+//
+// Funciona con la extensión sse de htmx:
+//
+//	g.GET("/sse", func(c *gecko.Context) error {
+//		return hub.Servir(c, c.QueryVal("tema"))
+//	})
+//
+//	<div hx-ext="sse" sse-connect="/sse?tema=pedidos" sse-swap="pedido_creado"></div>
+//
+// Para empujar los gko.Event al navegador se asigna como Broadcaster
+// del gko.EventStore y se suscribe al tema gecko.TemaEventosSSE.
+//
+// El gko.EventStore llama al Broadcaster al registrar el evento, antes de
+// que se confirme la transacción, por lo que el navegador podría recibir
+// eventos que luego se descartan con el rollback. Para publicar solo al
+// confirmar se crea el store con eventsqlite.EventStoreTx(tx, hub).
+type HubSSE struct {
+	mu    sync.RWMutex
+	temas map[string]map[*suscriptorSSE]struct{}
+}
+
+type suscriptorSSE struct {
+	mensajes chan MensajeSSE
+}
+
+func NuevoHubSSE() *HubSSE {
+	return &HubSSE{
+		temas: map[string]map[*suscriptorSSE]struct{}{},
+	}
+}
+
+// Envía el mensaje a todos los suscriptores del tema.
+// No bloquea: si un suscriptor tiene su buffer lleno se descarta para él.
+func (h *HubSSE) Publicar(tema string, msg MensajeSSE) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sus := range h.temas[tema] {
+		select {
+		case sus.mensajes <- msg:
+		default:
+			gko.LogWarnf("gecko.HubSSE: suscriptor lento en %s, mensaje descartado", tema)
+		}
+	}
+}
+
+// Implementa gko.EventBroadcaster publicando el evento en el tema
+// gecko.TemaEventosSSE con el EventKey como nombre de evento y el
+// mensaje del evento como data.
+func (h *HubSSE) Broadcast(ev gko.Event) {
+	h.Publicar(TemaEventosSSE, MensajeSSE{
+		ID:     strconv.FormatUint(uint64(ev.EventID), 10),
+		Evento: string(ev.EventKey),
+		Data:   ev.Mensaje(),
+	})
+}
+
+// Número de clientes suscritos al tema.
+func (h *HubSSE) Suscriptores(tema string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.temas[tema])
+}
+
+// Abre un stream SSE y envía los mensajes publicados en los temas
+// hasta que el cliente se desconecte o se apague el servidor.
+func (h *HubSSE) Servir(c *Context, temas ...string) error {
+	op := gko.Op("gecko.HubSSE.Servir")
+	if len(temas) == 0 {
+		return op.E(gko.ErrDatoIndef).Msg("Tema de suscripción requerido")
+	}
+	sse, err := c.SSE()
+	if err != nil {
+		return op.Err(err)
+	}
+	sus := h.suscribir(temas)
+	defer h.desuscribir(sus, temas)
+	for {
+		select {
+		case <-sse.Done():
+			return nil
+		case msg := <-sus.mensajes:
+			if err := sse.Enviar(msg); err != nil {
+				return nil // El cliente se desconectó.
+			}
+		}
+	}
+}
+
+func (h *HubSSE) suscribir(temas []string) *suscriptorSSE {
+	sus := &suscriptorSSE{mensajes: make(chan MensajeSSE, bufferSuscriptorSSE)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, tema := range temas {
+		if h.temas[tema] == nil {
+			h.temas[tema] = map[*suscriptorSSE]struct{}{}
+		}
+		h.temas[tema][sus] = struct{}{}
+	}
+	return sus
+}
+
+func (h *HubSSE) desuscribir(sus *suscriptorSSE, temas []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, tema := range temas {
+		delete(h.temas[tema], sus)
+		if len(h.temas[tema]) == 0 {
+			delete(h.temas, tema)
+		}
+	}
+}
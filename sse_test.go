@@ -0,0 +1,147 @@
+package gecko
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+func TestEnviarSSE(t *testing.T) {
+	g := New()
+	var errCerrado error
+	g.GET("/sse", func(c *Context) error {
+		sse, err := c.SSEKeepAlive(0)
+		if err != nil {
+			return err
+		}
+		if otro, _ := c.SSE(); otro != sse {
+			t.Error("c.SSE() abrió otro stream")
+		}
+		sse.Enviar(MensajeSSE{ID: "7\n8", Evento: "pedido", Data: "<p>uno</p>\r\n<p>dos</p>", Retry: 2 * time.Second})
+		sse.Evento("vacío", "")
+		sse.Comentario("hola\nmundo")
+		sse.Retry(time.Second)
+		sse.Cerrar()
+		errCerrado = sse.Evento("tarde", "x")
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+
+	esperado := "id: 7 8\nevent: pedido\nretry: 2000\ndata: <p>uno</p>\ndata: <p>dos</p>\n\n" +
+		"event: vacío\ndata: \n\n" +
+		": hola mundo\n\n" +
+		"retry: 1000\n\n"
+	if rec.Body.String() != esperado {
+		t.Errorf("body:\n%q\nse esperaba:\n%q", rec.Body.String(), esperado)
+	}
+	if rec.Header().Get("Content-Type") != "text/event-stream" || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("headers: %v", rec.Header())
+	}
+	if !gko.Is(errCerrado, gko.ErrNoDisponible) {
+		t.Errorf("enviar con el stream cerrado: %v", errCerrado)
+	}
+}
+
+func TestKeepAliveSSE(t *testing.T) {
+	g := New()
+	g.GET("/sse", func(c *Context) error {
+		_, err := c.SSEKeepAlive(5 * time.Millisecond)
+		if err != nil {
+			return err
+		}
+		time.Sleep(40 * time.Millisecond)
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+	body := rec.Body.String()
+	if n := strings.Count(body, ":\n\n"); n < 2 || strings.ReplaceAll(body, ":\n\n", "") != "" {
+		t.Errorf("%d keep-alive en %q", n, body)
+	}
+
+	// Al terminar el handler ya no se escribe nada.
+	time.Sleep(20 * time.Millisecond)
+	if rec.Body.String() != body {
+		t.Error("keep-alive después de terminar el handler")
+	}
+}
+
+// Espera hasta un segundo a que el tema tenga n suscriptores.
+func esperarSuscriptores(t *testing.T, hub *HubSSE, tema string, n int) {
+	t.Helper()
+	for range 100 {
+		if hub.Suscriptores(tema) == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("tema %s con %d suscriptores, se esperaban %d", tema, hub.Suscriptores(tema), n)
+}
+
+func TestHubSSE(t *testing.T) {
+	hub := NuevoHubSSE()
+	g := New()
+	g.GET("/sse", func(c *Context) error {
+		return hub.Servir(c, c.QueryParams()["tema"]...)
+	})
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	if res, err := http.Get(srv.URL + "/sse"); err != nil || res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("sin tema: %v %v", res.Status, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse?tema=pedidos&tema="+TemaEventosSSE, nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	esperarSuscriptores(t, hub, "pedidos", 1)
+	esperarSuscriptores(t, hub, TemaEventosSSE, 1)
+
+	hub.Publicar("otro", MensajeSSE{Data: "ignorado"})
+	hub.Publicar("pedidos", MensajeSSE{Evento: "pedido_creado", Data: "<li>1</li>"})
+	hub.Broadcast(gko.Event{EventID: 42, EventKey: "usuario_registrado"})
+
+	lector := bufio.NewReader(res.Body)
+	leerEvento := func() string {
+		var b strings.Builder
+		for {
+			línea, err := lector.ReadString('\n')
+			if err != nil {
+				t.Fatalf("leer evento: %v", err)
+			}
+			if línea == "\n" {
+				return b.String()
+			}
+			b.WriteString(línea)
+		}
+	}
+	if ev := leerEvento(); ev != "event: pedido_creado\ndata: <li>1</li>\n" {
+		t.Errorf("evento publicado: %q", ev)
+	}
+	if ev := leerEvento(); ev != "id: 42\nevent: usuario_registrado\ndata: usuario_registrado (no data)\n" {
+		t.Errorf("evento de broadcast: %q", ev)
+	}
+
+	// Al desconectarse el cliente se quita de todos sus temas.
+	cancel()
+	esperarSuscriptores(t, hub, "pedidos", 0)
+	esperarSuscriptores(t, hub, TemaEventosSSE, 0)
+	hub.mu.RLock()
+	temas := len(hub.temas)
+	hub.mu.RUnlock()
+	if temas != 0 {
+		t.Errorf("quedaron %d temas sin suscriptores", temas)
+	}
+}
@@ -0,0 +1,428 @@
+package gecko
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pargomx/gecko/gko"
+)
+
+// ================================================================ //
+// ========== WEBSOCKET =========================================== //
+
+// Implementación mínima de RFC 6455 con la librería estándar.
+// No soporta extensiones (permessage-deflate) ni subprotocolos.
+
+// GUID definido en RFC 6455 para calcular Sec-WebSocket-Accept.
+const guidWebSocket = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Tamaño máximo default de un mensaje recibido, sumando fragmentos.
+const defaultLimiteMensajeWS = 1 << 20 // 1 MB
+
+// Tipo de mensaje de datos.
+type TipoMensajeWS int
+
+const (
+	MensajeTexto   TipoMensajeWS = 1 // Opcode 0x1. UTF-8 válido.
+	MensajeBinario TipoMensajeWS = 2 // Opcode 0x2.
+)
+
+// Opcodes de frames.
+const (
+	opContinuacion byte = 0x0
+	opTexto        byte = 0x1
+	opBinario      byte = 0x2
+	opCerrar       byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xA
+)
+
+// Códigos de cierre más usados.
+const (
+	CierreNormal        uint16 = 1000
+	CierreSaliendo      uint16 = 1001 // El servidor se apaga o el cliente navega a otra página.
+	CierreProtocolo     uint16 = 1002
+	CierreDatoNoSoporta uint16 = 1003
+	cierreSinCódigo     uint16 = 1005 // Nunca se envía.
+	CierreDatoInvalido  uint16 = 1007
+	CierreMuyGrande     uint16 = 1009
+	CierreErrorServidor uint16 = 1011
+)
+
+// ConexionWS es una conexión WebSocket abierta con c.WebSocket().
+//
+// Leer se debe llamar desde una sola goroutine. Los métodos de envío
+// son seguros desde varias goroutines.
+type ConexionWS struct {
+	c    *Context
+	conn net.Conn
+	brw  *bufio.ReadWriter
+
+	LimiteMensaje int64 // Bytes máximos por mensaje recibido. Default 1 MB.
+
+	escrMu  sync.Mutex // Un frame a la vez.
+	cerrado bool       // Ya se envió el frame de cierre.
+
+	AlRecibirPong func(data []byte) // Opcional. Se llama desde Leer.
+}
+
+// Realiza el handshake de WebSocket y ejecuta fn con la conexión.
+// Al terminar fn se envía el frame de cierre si no se ha enviado
+// y se cierra la conexión. Se cierra también al apagar el servidor.
+//
+// El error de fn se retorna para que quede en el log http, cuyo
+// status será 101. Si el handshake falla no se toma la conexión
+// y se retorna un error para responder normalmente.
+//
+//	g.GET("/ws", func(c *gecko.Context) error {
+//		return c.WebSocket(func(ws *gecko.ConexionWS) error {
+//			for {
+//				tipo, msg, err := ws.Leer()
+//				if err == io.EOF {
+//					return nil // El cliente cerró la conexión.
+//				} else if err != nil {
+//					return err
+//				}
+//				ws.Enviar(tipo, msg)
+//			}
+//		})
+//	})
+//
+// Por seguridad se rechazan solicitudes con header Origin de otro host.
+func (c *Context) WebSocket(fn func(ws *ConexionWS) error) error {
+	op := gko.Op("gecko.WebSocket")
+	r := c.request
+	if r.Method != http.MethodGet {
+		return op.E(gko.ErrDatoInvalido).Msg("WebSocket requiere GET")
+	}
+	if !c.IsWebSocket() || !headerContieneToken(r.Header, "Connection", "upgrade") {
+		return op.E(gko.ErrDatoInvalido).Msg("Se esperaba solicitud de WebSocket")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.response.Header().Set("Sec-WebSocket-Version", "13")
+		return op.E(gko.ErrNoSoportado).Msg("Versión de WebSocket no soportada")
+	}
+	clave := r.Header.Get("Sec-WebSocket-Key")
+	if clave == "" {
+		return op.E(gko.ErrDatoInvalido).Msg("Falta Sec-WebSocket-Key")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			return op.E(gko.ErrNoAutorizado).Msg("Origen no permitido").Ctx("origin", origin)
+		}
+	}
+
+	conn, brw, err := http.NewResponseController(c.response.Writer).Hijack()
+	if err != nil {
+		return op.E(gko.ErrNoDisponible).Err(err).Str("hijack")
+	}
+	defer conn.Close()
+
+	respuesta := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + calcularAcceptWS(clave) + "\r\n\r\n"
+	_, err = brw.WriteString(respuesta)
+	if err == nil {
+		err = brw.Flush()
+	}
+	// Ya no se puede responder por http, por lo que se registra como 101.
+	c.response.Status = http.StatusSwitchingProtocols
+	c.response.Committed = true
+	if err != nil {
+		return op.E(gko.ErrAlEscribir).Err(err).Str("handshake")
+	}
+
+	ws := &ConexionWS{
+		c:             c,
+		conn:          conn,
+		brw:           brw,
+		LimiteMensaje: defaultLimiteMensajeWS,
+	}
+
+	// Cerrar al apagar el servidor porque Shutdown no espera conexiones tomadas.
+	terminado := make(chan struct{})
+	defer close(terminado)
+	apagando := c.gecko.getApagandoChan()
+	go func() {
+		select {
+		case <-apagando:
+			ws.Cerrar(CierreSaliendo, "servidor apagándose")
+			conn.Close()
+		case <-terminado:
+		}
+	}()
+
+	err = fn(ws)
+	if err != nil {
+		ws.Cerrar(CierreErrorServidor, "")
+		return op.Err(err)
+	}
+	ws.Cerrar(CierreNormal, "")
+	return nil
+}
+
+func calcularAcceptWS(clave string) string {
+	h := sha1.New()
+	h.Write([]byte(clave + guidWebSocket))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Reporta si alguno de los valores separados por coma del header es el token.
+func headerContieneToken(h http.Header, nombre, token string) bool {
+	for _, v := range h.Values(nombre) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ================================================================ //
+// ========== Enviar ============================================== //
+
+// Envía un mensaje de texto.
+func (ws *ConexionWS) EnviarTexto(txt string) error {
+	return ws.escribirFrame(opTexto, []byte(txt))
+}
+
+// Envía un mensaje binario.
+func (ws *ConexionWS) EnviarBinario(data []byte) error {
+	return ws.escribirFrame(opBinario, data)
+}
+
+// Envía un mensaje del tipo indicado.
+func (ws *ConexionWS) Enviar(tipo TipoMensajeWS, data []byte) error {
+	if tipo == MensajeTexto {
+		return ws.escribirFrame(opTexto, data)
+	}
+	return ws.escribirFrame(opBinario, data)
+}
+
+// Envía un ping. El cliente debe responder con pong.
+func (ws *ConexionWS) Ping(data []byte) error {
+	return ws.escribirFrame(opPing, data)
+}
+
+// Envía el frame de cierre. Los siguientes envíos fallan.
+// La conexión se cierra cuando termina el handler.
+func (ws *ConexionWS) Cerrar(código uint16, razón string) error {
+	payload := make([]byte, 2, 2+len(razón))
+	binary.BigEndian.PutUint16(payload, código)
+	payload = append(payload, razón...)
+	if len(payload) > 125 {
+		payload = payload[:125]
+	}
+	return ws.escribirFrame(opCerrar, payload)
+}
+
+// Límite de tiempo para la siguiente lectura. Cero para quitarlo.
+func (ws *ConexionWS) SetReadDeadline(t time.Time) error {
+	return ws.conn.SetReadDeadline(t)
+}
+
+// Límite de tiempo para los siguientes envíos. Cero para quitarlo.
+func (ws *ConexionWS) SetWriteDeadline(t time.Time) error {
+	return ws.conn.SetWriteDeadline(t)
+}
+
+// Escribe un frame sin máscara como corresponde al servidor.
+func (ws *ConexionWS) escribirFrame(opcode byte, payload []byte) error {
+	ws.escrMu.Lock()
+	defer ws.escrMu.Unlock()
+	if ws.cerrado {
+		return gko.ErrNoDisponible.Str("gecko.WebSocket: conexión cerrada")
+	}
+	if opcode == opCerrar {
+		ws.cerrado = true
+	}
+	if opcode >= opCerrar && len(payload) > 125 {
+		return gko.ErrTooBig.Str("gecko.WebSocket: frame de control mayor a 125 bytes")
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode // FIN
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	_, err := ws.brw.Write(header)
+	if err == nil {
+		_, err = ws.brw.Write(payload)
+	}
+	if err == nil {
+		err = ws.brw.Flush()
+	}
+	if err != nil {
+		return gko.ErrAlEscribir.Err(err).Op("gecko.WebSocket")
+	}
+	// Para el log http.
+	ws.c.response.Size += uint64(len(header) + len(payload))
+	return nil
+}
+
+// ================================================================ //
+// ========== Leer ================================================ //
+
+// Lee el siguiente mensaje de datos uniendo sus fragmentos.
+//
+// Responde automáticamente los ping con pong y el cierre con cierre.
+// Cuando el cliente cierra la conexión normalmente retorna io.EOF.
+func (ws *ConexionWS) Leer() (TipoMensajeWS, []byte, error) {
+	op := gko.Op("gecko.WebSocket.Leer")
+	var tipo TipoMensajeWS
+	var mensaje []byte
+	for {
+		fin, opcode, payload, err := ws.leerFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return 0, nil, io.EOF
+			}
+			return 0, nil, op.Err(err)
+		}
+
+		switch opcode {
+		case opPing:
+			ws.escribirFrame(opPong, payload)
+			continue
+
+		case opPong:
+			if ws.AlRecibirPong != nil {
+				ws.AlRecibirPong(payload)
+			}
+			continue
+
+		case opCerrar:
+			código, razón := cierreSinCódigo, ""
+			if len(payload) >= 2 {
+				código, razón = binary.BigEndian.Uint16(payload), string(payload[2:])
+			}
+			if código == cierreSinCódigo {
+				ws.Cerrar(CierreNormal, "") // Eco del cierre.
+			} else {
+				ws.Cerrar(código, "")
+			}
+			if código == CierreNormal || código == CierreSaliendo || código == cierreSinCódigo {
+				return 0, nil, io.EOF
+			}
+			return 0, nil, op.E(gko.ErrNoDisponible).Strf("cerrado por cliente: %d %s", código, razón)
+
+		case opTexto, opBinario:
+			if tipo != 0 {
+				ws.Cerrar(CierreProtocolo, "")
+				return 0, nil, op.E(gko.ErrDatoInvalido).Str("mensaje nuevo antes de terminar fragmentos")
+			}
+			tipo = TipoMensajeWS(opcode)
+
+		case opContinuacion:
+			if tipo == 0 {
+				ws.Cerrar(CierreProtocolo, "")
+				return 0, nil, op.E(gko.ErrDatoInvalido).Str("continuación sin mensaje")
+			}
+
+		default:
+			ws.Cerrar(CierreProtocolo, "")
+			return 0, nil, op.E(gko.ErrDatoInvalido).Strf("opcode desconocido %x", opcode)
+		}
+
+		if int64(len(mensaje)+len(payload)) > ws.LimiteMensaje {
+			ws.Cerrar(CierreMuyGrande, "")
+			return 0, nil, op.E(gko.ErrTooBig).Strf("mensaje mayor a %d bytes", ws.LimiteMensaje)
+		}
+		mensaje = append(mensaje, payload...)
+		if !fin {
+			continue
+		}
+		if tipo == MensajeTexto && !utf8.Valid(mensaje) {
+			ws.Cerrar(CierreDatoInvalido, "")
+			return 0, nil, op.E(gko.ErrDatoInvalido).Str("texto no es UTF-8")
+		}
+		return tipo, mensaje, nil
+	}
+}
+
+// Lee el siguiente mensaje esperando que sea texto.
+func (ws *ConexionWS) LeerTexto() (string, error) {
+	tipo, msg, err := ws.Leer()
+	if err != nil {
+		return "", err
+	}
+	if tipo != MensajeTexto {
+		return "", gko.ErrNoSoportado.Str("gecko.WebSocket: se esperaba mensaje de texto")
+	}
+	return string(msg), nil
+}
+
+// Lee un frame desenmascarando el payload. Los frames del cliente
+// siempre deben venir enmascarados.
+func (ws *ConexionWS) leerFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var h [2]byte
+	if _, err = io.ReadFull(ws.brw, h[:]); err != nil {
+		return
+	}
+	fin = h[0]&0x80 != 0
+	if h[0]&0x70 != 0 {
+		ws.Cerrar(CierreProtocolo, "")
+		return fin, 0, nil, gko.ErrDatoInvalido.Str("bits RSV sin extensión")
+	}
+	opcode = h[0] & 0x0F
+	if h[1]&0x80 == 0 {
+		ws.Cerrar(CierreProtocolo, "")
+		return fin, opcode, nil, gko.ErrDatoInvalido.Str("frame sin máscara")
+	}
+	largo := uint64(h[1] & 0x7F)
+	switch largo {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.brw, ext[:]); err != nil {
+			return
+		}
+		largo = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.brw, ext[:]); err != nil {
+			return
+		}
+		largo = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= opCerrar && (largo > 125 || !fin) {
+		ws.Cerrar(CierreProtocolo, "")
+		return fin, opcode, nil, gko.ErrDatoInvalido.Str("frame de control inválido")
+	}
+	if largo > uint64(ws.LimiteMensaje) {
+		ws.Cerrar(CierreMuyGrande, "")
+		return fin, opcode, nil, gko.ErrTooBig.Strf("frame mayor a %d bytes", ws.LimiteMensaje)
+	}
+	var máscara [4]byte
+	if _, err = io.ReadFull(ws.brw, máscara[:]); err != nil {
+		return
+	}
+	payload = make([]byte, largo)
+	if _, err = io.ReadFull(ws.brw, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= máscara[i%4]
+	}
+	return fin, opcode, payload, nil
+}
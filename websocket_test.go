@@ -0,0 +1,168 @@
+package gecko
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Cliente mínimo para probar el servidor: frames enmascarados y sin fragmentar.
+type clienteWS struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func conectarWS(t *testing.T, addr string) *clienteWS {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	clave := "dGhlIHNhbXBsZSBub25jZQ=="
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\n"+
+		"Host: "+addr+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+clave+"\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", res.StatusCode)
+	}
+	// Valor de ejemplo en RFC 6455 sección 1.3.
+	if got := res.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", got)
+	}
+	return &clienteWS{conn: conn, br: br}
+}
+
+func (cl *clienteWS) enviar(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	máscara := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, máscara[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^máscara[i%4])
+	}
+	if _, err := cl.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (cl *clienteWS) leer(t *testing.T) (byte, []byte) {
+	t.Helper()
+	var h [2]byte
+	if _, err := io.ReadFull(cl.br, h[:]); err != nil {
+		t.Fatal(err)
+	}
+	if h[1]&0x80 != 0 {
+		t.Fatal("el servidor no debe enmascarar frames")
+	}
+	largo := int(h[1] & 0x7F)
+	if largo == 126 {
+		var ext [2]byte
+		io.ReadFull(cl.br, ext[:])
+		largo = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, largo)
+	if _, err := io.ReadFull(cl.br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return h[0] & 0x0F, payload
+}
+
+func TestWebSocket(t *testing.T) {
+	logger := &loggerPrueba{}
+	g := New()
+	g.HTTPLogger = logger
+	g.GET("/ws", func(c *Context) error {
+		return c.WebSocket(func(ws *ConexionWS) error {
+			for {
+				tipo, msg, err := ws.Leer()
+				if err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := ws.Enviar(tipo, append([]byte("eco: "), msg...)); err != nil {
+					return err
+				}
+			}
+		})
+	})
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	cl := conectarWS(t, srv.Listener.Addr().String())
+	defer cl.conn.Close()
+
+	cl.enviar(t, opTexto, []byte("hola"))
+	if op, msg := cl.leer(t); op != opTexto || string(msg) != "eco: hola" {
+		t.Errorf("texto: opcode %x %q", op, msg)
+	}
+
+	cl.enviar(t, opBinario, []byte{1, 2, 3})
+	if op, msg := cl.leer(t); op != opBinario || string(msg) != "eco: \x01\x02\x03" {
+		t.Errorf("binario: opcode %x %q", op, msg)
+	}
+
+	cl.enviar(t, opPing, []byte("p"))
+	if op, msg := cl.leer(t); op != opPong || string(msg) != "p" {
+		t.Errorf("ping: opcode %x %q", op, msg)
+	}
+
+	cierre := binary.BigEndian.AppendUint16(nil, CierreNormal)
+	cl.enviar(t, opCerrar, cierre)
+	if op, msg := cl.leer(t); op != opCerrar || binary.BigEndian.Uint16(msg) != CierreNormal {
+		t.Errorf("cierre: opcode %x %v", op, msg)
+	}
+	if _, err := cl.br.ReadByte(); err != io.EOF {
+		t.Errorf("se esperaba conexión cerrada, err = %v", err)
+	}
+
+	// El log http se guarda en una goroutine después del handler.
+	var entries []LogEntry
+	for range 100 {
+		logger.mu.Lock()
+		entries = append(entries[:0], logger.entries...)
+		logger.mu.Unlock()
+		if len(entries) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("logs guardados = %d, want 1", len(entries))
+	}
+	if entries[0].Status != http.StatusSwitchingProtocols || entries[0].Error != "" {
+		t.Errorf("log status = %d error = %q", entries[0].Status, entries[0].Error)
+	}
+	if entries[0].BytesOut == 0 {
+		t.Error("log sin bytes enviados")
+	}
+}
+
+func TestWebSocketSinUpgrade(t *testing.T) {
+	g := New()
+	g.GET("/ws", func(c *Context) error {
+		return c.WebSocket(func(ws *ConexionWS) error { return nil })
+	})
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}